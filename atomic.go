@@ -0,0 +1,93 @@
+package forceset
+
+import "reflect"
+
+// Atomic makes a conversion all or nothing: it converts into a deep copy of the
+// destination and only stores the copy when the whole conversion succeeded.
+// Pointers, maps and slices reachable from the destination are replaced by
+// their copies on success.
+func Atomic(opt *SetOption) {
+	opt.Atomic = true
+}
+
+func forceSetAtomic(value reflect.Value, i interface{}, opt SetOption, tag string) (err error) {
+	defer recoverPanic(&err)
+	for !value.CanSet() && value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	shadow := reflect.New(value.Type()).Elem()
+	shadow.Set(deepCopy(value, map[copyKey]reflect.Value{}))
+	if err := forceSet(shadow, i, opt, tag); err != nil {
+		return err
+	}
+	value.Set(shadow)
+	return nil
+}
+
+// copyKey identifies a pointer by type as well as address, a struct and its
+// first field share the address.
+type copyKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// deepCopy copies v and everything reachable through its exported fields.
+// seen maps already copied pointers to their copies so cycles are kept.
+func deepCopy(v reflect.Value, seen map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Type(), v.Pointer()}
+		if c, ok := seen[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), seen))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for n := 0; n < v.Len(); n++ {
+			c.Index(n).Set(deepCopy(v.Index(n), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for n := 0; n < v.Len(); n++ {
+			c.Index(n).Set(deepCopy(v.Index(n), seen))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for n := 0; n < v.NumField(); n++ {
+			if c.Field(n).CanSet() {
+				c.Field(n).Set(deepCopy(v.Field(n), seen))
+			}
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,220 @@
+package forceset
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// setBigNumber converts into big.Int, big.Float and big.Rat destinations and
+// from them into Go numeric and string destinations. Narrowing a big number
+// fails instead of rounding. ok is false when neither side is a big number.
+func setBigNumber(value reflect.Value, i interface{}) (ok bool, err error) {
+	switch value.Type() {
+	case bigIntType, bigFloatType, bigRatType:
+		return true, setBig(value, i)
+	}
+	switch o := i.(type) {
+	case big.Int:
+		i = &o
+	case big.Float:
+		i = &o
+	case big.Rat:
+		i = &o
+	case *big.Int, *big.Float, *big.Rat:
+	default:
+		return false, nil
+	}
+	if reflect.ValueOf(i).IsNil() {
+		return true, nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(bigString(i))
+		return true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := bigToInt(i)
+		if err != nil {
+			return true, err
+		}
+		if value.Kind() >= reflect.Uint {
+			if n.Sign() < 0 || !n.IsUint64() || value.OverflowUint(n.Uint64()) {
+				return true, errors.New("big number " + n.String() + " overflows " + value.Type().String())
+			}
+			value.SetUint(n.Uint64())
+			return true, nil
+		}
+		if !n.IsInt64() || value.OverflowInt(n.Int64()) {
+			return true, errors.New("big number " + n.String() + " overflows " + value.Type().String())
+		}
+		value.SetInt(n.Int64())
+		return true, nil
+	case reflect.Float32, reflect.Float64:
+		var f64 float64
+		var exact bool
+		if r, isRat := i.(*big.Rat); isRat {
+			if value.Kind() == reflect.Float32 {
+				f32, ok := r.Float32()
+				f64, exact = float64(f32), ok
+			} else {
+				f64, exact = r.Float64()
+			}
+		} else {
+			f := bigToFloat(i)
+			var acc big.Accuracy
+			if value.Kind() == reflect.Float32 {
+				var f32 float32
+				f32, acc = f.Float32()
+				f64 = float64(f32)
+			} else {
+				f64, acc = f.Float64()
+			}
+			exact = acc == big.Exact && !math.IsInf(f64, 0)
+		}
+		if !exact {
+			return true, errors.New("big number " + bigString(i) + " cannot be represented exactly by " + value.Type().String())
+		}
+		value.SetFloat(f64)
+		return true, nil
+	}
+	return false, nil
+}
+
+func setBig(value reflect.Value, i interface{}) error {
+	var r *big.Rat
+	var f *big.Float
+	switch o := i.(type) {
+	case string:
+		return setBigString(value, o)
+	case []byte:
+		return setBigString(value, string(o))
+	case json.Number:
+		return setBigString(value, string(o))
+	case big.Int, big.Float, big.Rat:
+		ptr := reflect.New(reflect.TypeOf(o))
+		ptr.Elem().Set(reflect.ValueOf(o))
+		return setBig(value, ptr.Interface())
+	case *big.Int:
+		r = new(big.Rat).SetInt(o)
+	case *big.Rat:
+		r = o
+	case *big.Float:
+		if o.IsInf() {
+			return errors.New("infinite big float into " + value.Type().String())
+		}
+		f = o
+	case float32, float64:
+		f64 := reflect.ValueOf(o).Float()
+		if math.IsNaN(f64) || math.IsInf(f64, 0) {
+			return errors.New("float " + toString(o, SetOption{}) + " into " + value.Type().String())
+		}
+		r = new(big.Rat).SetFloat64(f64)
+	default:
+		iv := reflect.ValueOf(i)
+		switch iv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			r = new(big.Rat).SetInt64(iv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			r = new(big.Rat).SetInt(new(big.Int).SetUint64(iv.Uint()))
+		default:
+			return errors.New("type (" + iv.Type().String() + ") to " + value.Type().String() + " invalid")
+		}
+	}
+	if f != nil {
+		if value.Type() == bigFloatType {
+			value.Set(reflect.ValueOf(*new(big.Float).Copy(f)))
+			return nil
+		}
+		r, _ = f.Rat(nil)
+	}
+	switch value.Type() {
+	case bigIntType:
+		if !r.IsInt() {
+			return errors.New("big number " + r.RatString() + " is not an integer")
+		}
+		value.Set(reflect.ValueOf(*new(big.Int).Set(r.Num())))
+	case bigFloatType:
+		value.Set(reflect.ValueOf(*new(big.Float).SetRat(r)))
+	case bigRatType:
+		value.Set(reflect.ValueOf(*new(big.Rat).Set(r)))
+	}
+	return nil
+}
+
+func setBigString(value reflect.Value, s string) error {
+	switch value.Type() {
+	case bigIntType:
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return errors.New("parse big int " + s + " failed")
+		}
+		value.Set(reflect.ValueOf(*n))
+	case bigFloatType:
+		// about 3.32 bits per decimal digit, so the digits are kept
+		f, _, err := big.ParseFloat(s, 10, uint(len(s))*4+64, big.ToNearestEven)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(*f))
+	case bigRatType:
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return errors.New("parse big rat " + s + " failed")
+		}
+		value.Set(reflect.ValueOf(*r))
+	}
+	return nil
+}
+
+// bigToInt returns i as an integer, failing for fractions.
+func bigToInt(i interface{}) (*big.Int, error) {
+	switch o := i.(type) {
+	case *big.Int:
+		return o, nil
+	case *big.Rat:
+		if !o.IsInt() {
+			return nil, errors.New("big number " + o.RatString() + " is not an integer")
+		}
+		return o.Num(), nil
+	case *big.Float:
+		if !o.IsInt() {
+			return nil, errors.New("big number " + bigString(o) + " is not an integer")
+		}
+		n, _ := o.Int(nil)
+		return n, nil
+	}
+	return nil, errors.New("type (" + reflect.TypeOf(i).String() + ") to big int invalid")
+}
+
+// bigToFloat returns a big.Int or big.Float i as big.Float.
+func bigToFloat(i interface{}) *big.Float {
+	if n, ok := i.(*big.Int); ok {
+		return new(big.Float).SetInt(n)
+	}
+	return i.(*big.Float)
+}
+
+func bigString(i interface{}) string {
+	switch o := i.(type) {
+	case *big.Float:
+		return o.Text('g', -1)
+	case *big.Rat:
+		// a decimal unless the expansion does not terminate, as for 1/3
+		if n, exact := o.FloatPrec(); exact {
+			return o.FloatString(n)
+		}
+		return o.RatString()
+	case *big.Int:
+		return o.String()
+	}
+	return ""
+}
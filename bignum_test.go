@@ -0,0 +1,66 @@
+package forceset
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestSetBigNumbers(t *testing.T) {
+	type Account struct {
+		ID      *big.Int
+		Balance *big.Rat
+		Rate    *big.Float
+		Total   big.Int
+	}
+	var a Account
+	err := Set(&a, map[string]interface{}{
+		"ID":      "123456789012345678901234567890",
+		"Balance": json.Number("1234.56"),
+		"Rate":    "0.1000000000000000000001",
+		"Total":   uint64(1 << 63),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID.String() != "123456789012345678901234567890" || a.Balance.RatString() != "30864/25" ||
+		a.Rate.Text('g', -1) != "0.1000000000000000000001" || a.Total.String() != "9223372036854775808" {
+		t.Fatalf("%v %v %v %v", a.ID, a.Balance, a.Rate, &a.Total)
+	}
+
+	var s string
+	if err := Set(&s, a.Balance); err != nil || s != "1234.56" {
+		t.Fatal(s, err)
+	}
+	if err := Set(&s, big.NewRat(1, 3)); err != nil || s != "1/3" {
+		t.Fatal(s, err)
+	}
+	var i64 int64
+	if err := Set(&i64, a.ID); err == nil {
+		t.Fatal("expected overflow error got:", i64)
+	}
+	if err := Set(&i64, a.Balance); err == nil {
+		t.Fatal("expected fraction error got:", i64)
+	}
+	var u8 uint8
+	if err := Set(&u8, big.NewInt(255)); err != nil || u8 != 255 {
+		t.Fatal(u8, err)
+	}
+	if err := Set(&u8, big.NewInt(256)); err == nil {
+		t.Fatal("expected overflow error got:", u8)
+	}
+	var f float64
+	if err := Set(&f, big.NewRat(1, 4)); err != nil || f != 0.25 {
+		t.Fatal(f, err)
+	}
+	if err := Set(&f, a.Balance); err == nil || f != 0.25 {
+		t.Fatal("expected inexact error leaving the value got:", f, err)
+	}
+	var n *big.Int
+	if err := Set(&n, 2.5); err == nil {
+		t.Fatal("expected fraction error got:", n)
+	}
+	if err := Set(&n, big.NewRat(10, 2)); err != nil || n.Int64() != 5 {
+		t.Fatal(n, err)
+	}
+}
@@ -0,0 +1,141 @@
+package forceset
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// encodeBinary writes a bool or number for the Binary BytesOption. Fixed
+// width encodings use the width of the type, int and uint as 8 bytes.
+// Varint writes signed integers zigzag encoded, unsigned ones and the float64
+// bits of floats as unsigned varints.
+func encodeBinary(i interface{}, opt SetOption) []byte {
+	var u uint64
+	var signed bool
+	var width int
+	switch o := i.(type) {
+	case bool:
+		if o {
+			u = 1
+		}
+		width = 1
+	case int:
+		u, signed, width = uint64(o), true, 8
+	case int8:
+		u, signed, width = uint64(o), true, 1
+	case int16:
+		u, signed, width = uint64(o), true, 2
+	case int32:
+		u, signed, width = uint64(o), true, 4
+	case int64:
+		u, signed, width = uint64(o), true, 8
+	case uint:
+		u, width = uint64(o), 8
+	case uint8:
+		u, width = uint64(o), 1
+	case uint16:
+		u, width = uint64(o), 2
+	case uint32:
+		u, width = uint64(o), 4
+	case uint64:
+		u, width = o, 8
+	case uintptr:
+		u, width = uint64(o), 8
+	case float32:
+		u, width = uint64(math.Float32bits(o)), 4
+	case float64:
+		u, width = math.Float64bits(o), 8
+	}
+	if opt.BinaryEncoding == Varint {
+		if f, ok := i.(float32); ok {
+			u = math.Float64bits(float64(f))
+		}
+		buf := make([]byte, binary.MaxVarintLen64)
+		if signed {
+			return buf[:binary.PutVarint(buf, int64(u))]
+		}
+		return buf[:binary.PutUvarint(buf, u)]
+	}
+	buf := make([]byte, 8)
+	order := byteOrder(opt)
+	switch width {
+	case 1:
+		buf[0] = byte(u)
+	case 2:
+		order.PutUint16(buf, uint16(u))
+	case 4:
+		order.PutUint32(buf, uint32(u))
+	default:
+		order.PutUint64(buf, u)
+	}
+	return buf[:width]
+}
+
+func byteOrder(opt SetOption) binary.ByteOrder {
+	if opt.BinaryEncoding == FixedBigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// decodeBinary reads the bits written by encodeBinary. A fixed width value is
+// 1, 2, 4 or 8 bytes, narrower signed values are sign extended.
+func decodeBinary(data []byte, signed bool, opt SetOption) (uint64, error) {
+	if opt.BinaryEncoding == Varint {
+		var u uint64
+		var n int
+		if signed {
+			var i int64
+			i, n = binary.Varint(data)
+			u = uint64(i)
+		} else {
+			u, n = binary.Uvarint(data)
+		}
+		if n <= 0 || n != len(data) {
+			return 0, errors.New("invalid varint of " + strconv.Itoa(len(data)) + " bytes")
+		}
+		return u, nil
+	}
+	order := byteOrder(opt)
+	switch len(data) {
+	case 1:
+		if signed {
+			return uint64(int8(data[0])), nil
+		}
+		return uint64(data[0]), nil
+	case 2:
+		if signed {
+			return uint64(int16(order.Uint16(data))), nil
+		}
+		return uint64(order.Uint16(data)), nil
+	case 4:
+		if signed {
+			return uint64(int32(order.Uint32(data))), nil
+		}
+		return uint64(order.Uint32(data)), nil
+	case 8:
+		return order.Uint64(data), nil
+	}
+	return 0, errors.New("invalid fixed width number of " + strconv.Itoa(len(data)) + " bytes")
+}
+
+func decodeBinaryFloat(data []byte, opt SetOption) (float64, error) {
+	u, err := decodeBinary(data, false, opt)
+	if err != nil {
+		return 0, err
+	}
+	if opt.BinaryEncoding != Varint && len(data) == 4 {
+		return float64(math.Float32frombits(uint32(u))), nil
+	}
+	if opt.BinaryEncoding != Varint && len(data) != 8 {
+		return 0, errors.New("invalid fixed width float of " + strconv.Itoa(len(data)) + " bytes")
+	}
+	return math.Float64frombits(u), nil
+}
+
+func decodeBinaryBool(data []byte, opt SetOption) (bool, error) {
+	u, err := decodeBinary(data, false, opt)
+	return u != 0, err
+}
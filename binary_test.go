@@ -0,0 +1,69 @@
+package forceset
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetBinaryRoundTrip(t *testing.T) {
+	for _, enc := range []BinaryEncoding{FixedLittleEndian, FixedBigEndian, Varint} {
+		opt := BinaryAs(enc)
+		var data []byte
+		for _, src := range []interface{}{int(-300), int16(-2), uint32(70000), float32(1.5), float64(-2.25), true} {
+			if err := Set(&data, src, opt); err != nil {
+				t.Fatal(enc, src, err)
+			}
+			var i64 int64
+			var u64 uint64
+			var f64 float64
+			var b bool
+			switch o := src.(type) {
+			case int, int16:
+				if err := Set(&i64, data, opt); err != nil || i64 != toIntUnchecked(o) {
+					t.Fatal(enc, src, i64, err)
+				}
+			case uint32:
+				if err := Set(&u64, data, opt); err != nil || u64 != uint64(o) {
+					t.Fatal(enc, src, u64, err)
+				}
+			case float32, float64:
+				f, _ := toFloat(o, SetOption{})
+				if err := Set(&f64, data, opt); err != nil || f64 != f {
+					t.Fatal(enc, src, f64, err)
+				}
+			case bool:
+				if err := Set(&b, data, opt); err != nil || !b {
+					t.Fatal(enc, src, b, err)
+				}
+			}
+		}
+	}
+}
+
+func toIntUnchecked(i interface{}) int64 {
+	n, _ := toInt(i, SetOption{})
+	return n
+}
+
+func TestSetBinaryLayout(t *testing.T) {
+	var data []byte
+	if err := Set(&data, uint16(0x0102), BinaryAs(FixedBigEndian)); err != nil || !bytes.Equal(data, []byte{1, 2}) {
+		t.Fatal(data, err)
+	}
+	if err := Set(&data, 300, BinaryAs(FixedLittleEndian)); err != nil || len(data) != 8 {
+		t.Fatal(data, err)
+	}
+	if err := Set(&data, -1, BinaryAs(Varint)); err != nil || !bytes.Equal(data, []byte{1}) {
+		t.Fatal(data, err)
+	}
+	var n int
+	if err := Set(&n, []byte{1, 2, 3}, BinaryAs(FixedLittleEndian)); err == nil {
+		t.Fatal("expected error for 3 bytes got:", n)
+	}
+	if err := Set(&n, []byte{0x80}, BinaryAs(Varint)); err == nil {
+		t.Fatal("expected error for truncated varint got:", n)
+	}
+	if err := Set(&n, []byte{1, 0}, BinaryAs(Varint)); err == nil {
+		t.Fatal("expected error for trailing bytes got:", n)
+	}
+}
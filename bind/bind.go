@@ -0,0 +1,303 @@
+// Package bind fills structs from HTTP requests using forceset conversions.
+//
+// Fields name their source with a tag: `path:"id"`, `query:"page"`,
+// `header:"X-Request-Id"` or `form:"name"`. A JSON body is decoded into the
+// struct by its `json` tags, it never sets fields that have one of the other
+// tags and no `json` tag. Scalar fields take the first value of a
+// parameter, slice fields take all of them. Tag options follow the name as
+// in `query:"ids;sep:,"`.
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/cocotyty/forceset"
+)
+
+// FieldError describes a request parameter that could not be bound.
+type FieldError struct {
+	Field  string `json:"field"`
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Source + " " + e.Name + ": " + e.Reason
+}
+
+// Errors holds every field that failed, it is meant to be rendered as a 400 response.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type binder struct {
+	pathParams func(r *http.Request) map[string]string
+	maxMemory  int64
+	options    []forceset.Option
+}
+
+type Option func(b *binder)
+
+// PathParams sets the extractor of path parameters, usually provided by the router.
+func PathParams(fn func(r *http.Request) map[string]string) Option {
+	return func(b *binder) {
+		b.pathParams = fn
+	}
+}
+
+// MaxMemory limits the memory used to parse a multipart form, default is 32MB.
+func MaxMemory(n int64) Option {
+	return func(b *binder) {
+		b.maxMemory = n
+	}
+}
+
+// SetOptions passes options to the underlying conversions.
+func SetOptions(opts ...forceset.Option) Option {
+	return func(b *binder) {
+		b.options = append(b.options, opts...)
+	}
+}
+
+var fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+
+// Bind fills the struct dst points to from r. A malformed body is returned as
+// is, conversion failures are collected and returned as Errors.
+func Bind(r *http.Request, dst interface{}, opts ...Option) error {
+	b := binder{maxMemory: 32 << 20}
+	for _, fn := range opts {
+		fn(&b)
+	}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("bind into type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to struct")
+	}
+	var errs Errors
+	var files map[string][]*multipart.FileHeader
+	var form map[string][]string
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var body interface{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		err := decoder.Decode(&body)
+		switch {
+		case err == io.EOF:
+			// no body
+		case err != nil:
+			return err
+		default:
+			// converted into a copy so that fields bound from other sources are not set
+			shadow := reflect.New(v.Elem().Type())
+			shadow.Elem().Set(v.Elem())
+			zeroNonBody(shadow.Elem())
+			if err := forceset.Set(shadow.Interface(), body, b.options...); err != nil {
+				errs = append(errs, fieldError("json", "", err))
+			}
+			copyBody(v.Elem(), shadow.Elem())
+		}
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(b.maxMemory); err != nil {
+			return err
+		}
+		form = r.MultipartForm.Value
+		files = r.MultipartForm.File
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return err
+		}
+		form = r.PostForm
+	}
+	query := r.URL.Query()
+	var params map[string]string
+	if b.pathParams != nil {
+		params = b.pathParams(r)
+	}
+	sources := []source{
+		{"form", func(name string) ([]string, bool) {
+			values, ok := form[name]
+			return values, ok
+		}},
+		{"query", func(name string) ([]string, bool) {
+			values, ok := query[name]
+			return values, ok
+		}},
+		{"header", func(name string) ([]string, bool) {
+			values, ok := r.Header[http.CanonicalHeaderKey(name)]
+			return values, ok
+		}},
+		{"path", func(name string) ([]string, bool) {
+			value, ok := params[name]
+			return []string{value}, ok
+		}},
+	}
+	b.bindStruct(v.Elem(), sources, files, &errs)
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+type source struct {
+	tag    string
+	lookup func(name string) ([]string, bool)
+}
+
+// bindStruct binds the fields of dst and reports whether any was set.
+func (b *binder) bindStruct(dst reflect.Value, sources []source, files map[string][]*multipart.FileHeader, errs *Errors) bool {
+	dstType := dst.Type()
+	bound := false
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		st := dstType.Field(i)
+		if st.Anonymous && st.Type.Kind() == reflect.Struct {
+			bound = b.bindStruct(field, sources, files, errs) || bound
+			continue
+		}
+		if st.Anonymous && isStructPtr(st.Type) {
+			if st.PkgPath != "" {
+				continue
+			}
+			// allocated when one of its fields is bound
+			embedded := field
+			if field.IsNil() {
+				embedded = reflect.New(st.Type.Elem())
+			}
+			if b.bindStruct(embedded.Elem(), sources, files, errs) {
+				field.Set(embedded)
+				bound = true
+			}
+			continue
+		}
+		if st.PkgPath != "" {
+			continue
+		}
+		for _, src := range sources {
+			tag := st.Tag.Get(src.tag)
+			name := strings.Split(tag, ";")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if src.tag == "form" && bindFiles(field, files[name]) {
+				bound = true
+				continue
+			}
+			values, ok := src.lookup(name)
+			if !ok {
+				continue
+			}
+			bound = true
+			if err := forceset.SetField(field, st.Name, tag, forceset.Values(values), b.options...); err != nil {
+				*errs = append(*errs, fieldError(src.tag, name, err))
+			}
+		}
+	}
+	return bound
+}
+
+// fromBody reports whether a JSON body may set the field: it has a json tag
+// or no path, query, header or form tag.
+func fromBody(st reflect.StructField) bool {
+	if name, ok := st.Tag.Lookup("json"); ok {
+		return name != "-"
+	}
+	for _, tag := range []string{"path", "query", "header", "form"} {
+		if _, ok := st.Tag.Lookup(tag); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isStructPtr(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct
+}
+
+// zeroNonBody zeroes the fields of v a body may not set, embedded struct
+// pointers are replaced by copies first so that v shares none of them.
+func zeroNonBody(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		st := v.Type().Field(i)
+		switch {
+		case st.Anonymous && st.Type.Kind() == reflect.Struct:
+			zeroNonBody(field)
+		case st.Anonymous && isStructPtr(st.Type):
+			if !field.CanSet() || field.IsNil() {
+				continue
+			}
+			c := reflect.New(st.Type.Elem())
+			c.Elem().Set(field.Elem())
+			zeroNonBody(c.Elem())
+			field.Set(c)
+		case field.CanSet() && !fromBody(st):
+			field.Set(reflect.Zero(st.Type))
+		}
+	}
+}
+
+// copyBody copies the fields a body may set from src into dst.
+func copyBody(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		st := dst.Type().Field(i)
+		switch {
+		case st.Anonymous && st.Type.Kind() == reflect.Struct:
+			copyBody(field, src.Field(i))
+		case st.Anonymous && isStructPtr(st.Type):
+			if !field.CanSet() || src.Field(i).IsNil() {
+				continue
+			}
+			if field.IsNil() {
+				field.Set(reflect.New(st.Type.Elem()))
+			}
+			copyBody(field.Elem(), src.Field(i).Elem())
+		case field.CanSet() && fromBody(st):
+			field.Set(src.Field(i))
+		}
+	}
+}
+
+// bindFiles sets a *multipart.FileHeader or []*multipart.FileHeader field.
+func bindFiles(field reflect.Value, headers []*multipart.FileHeader) bool {
+	switch {
+	case field.Type() == fileHeaderType:
+		if len(headers) != 0 {
+			field.Set(reflect.ValueOf(headers[0]))
+		}
+		return true
+	case field.Kind() == reflect.Slice && field.Type().Elem() == fileHeaderType:
+		field.Set(reflect.ValueOf(headers))
+		return true
+	}
+	return false
+}
+
+func fieldError(src, name string, err error) *FieldError {
+	fe := &FieldError{Source: src, Name: name, Reason: err.Error()}
+	var ce *forceset.ConversionError
+	if errors.As(err, &ce) {
+		fe.Field = ce.Path
+		fe.Reason = ce.Reason
+	}
+	if fe.Name == "" {
+		fe.Name = fe.Field
+	}
+	return fe
+}
@@ -0,0 +1,158 @@
+package bind
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Paging struct {
+	Page int `query:"page"`
+	Size int `query:"size"`
+}
+
+type ListRequest struct {
+	Paging
+	ID        int64    `path:"id"`
+	Tags      []string `query:"tag"`
+	RequestID string   `header:"X-Request-Id"`
+	Name      string   `json:"name" form:"name"`
+	Age       int      `json:"age" form:"age"`
+}
+
+func pathParams(r *http.Request) map[string]string {
+	return map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/users/")}
+}
+
+func TestBindJSON(t *testing.T) {
+	r := httptest.NewRequest("POST", "/users/7?page=2&tag=a&tag=b", strings.NewReader(`{"name":"Peter","age":"30"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.Header.Set("X-Request-Id", "abc")
+	var req ListRequest
+	err := Bind(r, &req, PathParams(pathParams))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := ListRequest{Paging: Paging{Page: 2}, ID: 7, Tags: []string{"a", "b"}, RequestID: "abc", Name: "Peter", Age: 30}
+	if !reflect.DeepEqual(req, expected) {
+		t.Fatalf("%#v", req)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	form := url.Values{"name": {"Peter"}, "age": {"30"}}
+	r := httptest.NewRequest("POST", "/users/7", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var req ListRequest
+	err := Bind(r, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "Peter" || req.Age != 30 {
+		t.Fatalf("%#v", req)
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("name", "Peter")
+	fw, _ := w.CreateFormFile("avatar", "a.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = w.Close()
+	r := httptest.NewRequest("POST", "/upload", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	var req struct {
+		Name   string                `form:"name"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	err := Bind(r, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "Peter" || req.Avatar == nil || req.Avatar.Filename != "a.png" {
+		t.Fatalf("%#v", req)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	r := httptest.NewRequest("POST", "/users/x?page=first", strings.NewReader(`{"age":"old"}`))
+	r.Header.Set("Content-Type", "application/json")
+	var req ListRequest
+	err := Bind(r, &req, PathParams(pathParams))
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatal("expected 3 field errors got:", err)
+	}
+	expected := []FieldError{
+		{Field: "Age", Source: "json", Name: "Age"},
+		{Field: "Page", Source: "query", Name: "page"},
+		{Field: "ID", Source: "path", Name: "id"},
+	}
+	for i, fe := range errs {
+		fe.Reason = ""
+		if *fe != expected[i] {
+			t.Fatalf("expected %#v got: %#v", expected[i], *fe)
+		}
+	}
+	r = httptest.NewRequest("POST", "/users/1", strings.NewReader(`{`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := Bind(r, &req); err == nil || errors.As(err, &errs) {
+		t.Fatal("expected body error got:", err)
+	}
+}
+
+func TestBindTagOptions(t *testing.T) {
+	var req struct {
+		IDs  []int `query:"ids;sep:,"`
+		Size int64 `query:"size;unit:bytes"`
+	}
+	r := httptest.NewRequest("GET", "/?ids=1,2&ids=3&size=2KiB", nil)
+	r.Header.Set("Content-Type", "application/json")
+	if err := Bind(r, &req); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.IDs, []int{1, 2, 3}) || req.Size != 2048 {
+		t.Fatal(req)
+	}
+}
+
+func TestBindBodyCannotSetOtherSources(t *testing.T) {
+	var req struct {
+		ListRequest
+		Admin bool `query:"admin"`
+	}
+	r := httptest.NewRequest("POST", "/users/7", strings.NewReader(`{"RequestID":"spoof","Admin":true,"ID":99,"name":"Peter"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := Bind(r, &req, PathParams(pathParams)); err != nil {
+		t.Fatal(err)
+	}
+	if req.RequestID != "" || req.Admin || req.ID != 7 || req.Name != "Peter" {
+		t.Fatalf("%#v", req)
+	}
+}
+
+func TestBindEmbeddedPointer(t *testing.T) {
+	var req struct {
+		*Paging
+		Name string `query:"name"`
+	}
+	r := httptest.NewRequest("GET", "/?page=3", nil)
+	if err := Bind(r, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Paging == nil || req.Page != 3 {
+		t.Fatalf("%#v", req.Paging)
+	}
+	req.Paging = nil
+	r = httptest.NewRequest("GET", "/?name=x", nil)
+	if err := Bind(r, &req); err != nil || req.Paging != nil {
+		t.Fatal(req.Paging, err)
+	}
+}
@@ -0,0 +1,109 @@
+package forceset
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+type textEncoding interface {
+	EncodeToString([]byte) string
+	DecodeString(string) ([]byte, error)
+}
+
+type hexEncoding struct{}
+
+func (hexEncoding) EncodeToString(data []byte) string { return hex.EncodeToString(data) }
+
+func (hexEncoding) DecodeString(s string) ([]byte, error) { return hex.DecodeString(s) }
+
+// bytesOptions maps the values of the "bytes:<name>" tag option.
+var bytesOptions = map[string]BytesOption{
+	"string":    AsString,
+	"base64":    Base64,
+	"binary":    Binary,
+	"hex":       Hex,
+	"base64url": Base64URL,
+	"rawbase64": RawBase64,
+	"base32":    Base32,
+}
+
+func bytesOptionName(b BytesOption) string {
+	for name, o := range bytesOptions {
+		if o == b {
+			return name
+		}
+	}
+	return ""
+}
+
+func textEncodingOf(b BytesOption) textEncoding {
+	switch b {
+	case Base64:
+		return base64.StdEncoding
+	case Hex:
+		return hexEncoding{}
+	case Base64URL:
+		return base64.URLEncoding
+	case RawBase64:
+		return base64.RawStdEncoding
+	case Base32:
+		return base32.StdEncoding
+	}
+	return nil
+}
+
+// detectOrder is the order in which DetectBytes tries encodings. A string
+// valid in several of them, e.g. "cafe", decodes with the first.
+var detectOrder = []BytesOption{Hex, Base32, Base64, Base64URL, RawBase64}
+
+// bytesByTag applies the "bytes:<name>" tag option, "bytes:auto" enables
+// DetectBytes for the field.
+func bytesByTag(opt SetOption, tag string) (SetOption, error) {
+	name, ok := tagOption(tag, "bytes")
+	if !ok {
+		return opt, nil
+	}
+	if name == "auto" {
+		opt.DetectBytes = true
+		return opt, nil
+	}
+	b, ok := bytesOptions[name]
+	if !ok {
+		return opt, errors.New("unknown bytes encoding " + name)
+	}
+	opt.BytesOption = b
+	return opt, nil
+}
+
+func encodeBytes(data []byte, opt SetOption) string {
+	if enc := textEncodingOf(opt.BytesOption); enc != nil {
+		return enc.EncodeToString(data)
+	}
+	return string(data)
+}
+
+func decodeBytes(s string, opt SetOption) ([]byte, error) {
+	if opt.DetectBytes {
+		if _, data, ok := detectBytes(s, detectOrder); ok {
+			return data, nil
+		}
+	}
+	if enc := textEncodingOf(opt.BytesOption); enc != nil {
+		return enc.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+func detectBytes(s string, order []BytesOption) (BytesOption, []byte, bool) {
+	if s == "" {
+		return AsString, nil, false
+	}
+	for _, b := range order {
+		if data, err := textEncodingOf(b).DecodeString(s); err == nil {
+			return b, data, true
+		}
+	}
+	return AsString, nil, false
+}
@@ -0,0 +1,81 @@
+package forceset
+
+import (
+	"bytes"
+	"testing"
+)
+
+type blobs struct {
+	Hex    []byte `json:"hex;bytes:hex"`
+	URL    []byte `json:"url;bytes:base64url"`
+	Raw    []byte `json:"raw;bytes:rawbase64"`
+	Base32 []byte `json:"base32;bytes:base32"`
+	Auto   []byte `json:"auto;bytes:auto"`
+	Plain  []byte `json:"plain"`
+}
+
+func TestSetBytesEncodingByTag(t *testing.T) {
+	var b blobs
+	err := Set(&b, map[string]string{
+		"hex":    "cafe",
+		"url":    "-_8=",
+		"raw":    "+/8",
+		"base32": "ZL7A====",
+		"auto":   "ZL7A====",
+		"plain":  "cafe",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xca, 0xfe}
+	if !bytes.Equal(b.Hex, want) || !bytes.Equal(b.Base32, want) || !bytes.Equal(b.Auto, want) {
+		t.Fatal(b)
+	}
+	if !bytes.Equal(b.URL, []byte{0xfb, 0xff}) || !bytes.Equal(b.Raw, []byte{0xfb, 0xff}) {
+		t.Fatal(b)
+	}
+	if string(b.Plain) != "cafe" {
+		t.Fatal(b.Plain)
+	}
+	if err := Set(&b, map[string]string{"hex": "xyz"}); err == nil {
+		t.Fatal("expected error for invalid hex got:", b.Hex)
+	}
+
+	var out map[string]string
+	if err := Set(&out, b); err != nil {
+		t.Fatal(err)
+	}
+	if out["hex"] != "cafe" || out["url"] != "-_8=" || out["raw"] != "+/8" || out["base32"] != "ZL7A====" {
+		t.Fatal(out)
+	}
+}
+
+func TestSetBytesEncodingGlobal(t *testing.T) {
+	var s string
+	if err := Set(&s, []byte{0xca, 0xfe}, func(opt *SetOption) { opt.BytesOption = Hex }); err != nil || s != "cafe" {
+		t.Fatal(s, err)
+	}
+	var data []byte
+	if err := Set(&data, "yv4=", DetectBytes); err != nil || !bytes.Equal(data, []byte{0xca, 0xfe}) {
+		t.Fatal(data, err)
+	}
+	if err := Set(&data, "not encoded", DetectBytes); err != nil || string(data) != "not encoded" {
+		t.Fatal(data, err)
+	}
+}
+
+func TestSetBytesEncodingDecoder(t *testing.T) {
+	type spec struct {
+		Name string `json:"name"`
+	}
+	var c struct {
+		Spec spec `json:"spec;decode:hex"`
+	}
+	if err := Set(&c, map[string]string{"spec": "7b226e616d65223a2261227d"}); err != nil || c.Spec.Name != "a" {
+		t.Fatal(c, err)
+	}
+	var sp spec
+	if err := Set(&sp, "7b226e616d65223a2262227d", func(opt *SetOption) { opt.BytesOption = Hex }); err != nil || sp.Name != "b" {
+		t.Fatal(sp, err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package csv reads and writes slices of structs as CSV using forceset conversions.
+//
+// With a header row, columns are matched to fields by the `csv` tag or the
+// field name, as forceset matches map keys. Without header, columns are
+// matched by field position and the `idx` tag.
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cocotyty/forceset"
+)
+
+// RowError reports the row, counted from 1 including the header, and the
+// field path of a failed conversion.
+type RowError struct {
+	Row   int
+	Field string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	// the field path is part of Err
+	return "csv: row " + strconv.Itoa(e.Row) + ": " + e.Err.Error()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type config struct {
+	header  bool
+	comma   rune
+	options []forceset.Option
+}
+
+type Option func(c *config)
+
+// NoHeader reads and writes rows without header, fields are matched by position.
+func NoHeader(c *config) {
+	c.header = false
+}
+
+// Comma sets the field delimiter, default is ','.
+func Comma(r rune) Option {
+	return func(c *config) {
+		c.comma = r
+	}
+}
+
+// SetOptions passes options to the underlying conversions, the tag defaults to "csv".
+func SetOptions(opts ...forceset.Option) Option {
+	return func(c *config) {
+		c.options = append(c.options, opts...)
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{header: true, comma: ','}
+	c.options = []forceset.Option{func(opt *forceset.SetOption) {
+		opt.Tag = "csv"
+	}}
+	for _, fn := range opts {
+		fn(&c)
+	}
+	return c
+}
+
+// Decode reads all rows of r into dst, a pointer to a slice of structs or of
+// pointers to structs. It stops at the first failing row with a *RowError.
+func Decode(r io.Reader, dst interface{}, opts ...Option) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.New("csv decode into type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to slice")
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	rows := reflect.MakeSlice(slice.Type(), 0, 0)
+	err := each(r, elemType, newConfig(opts), func(row int, item reflect.Value) error {
+		rows = reflect.Append(rows, item)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slice.Set(rows)
+	return nil
+}
+
+// Each streams the rows of r, calling fn with the row number and the
+// decoded value of every row. An error from fn stops reading and is returned.
+func Each[T any](r io.Reader, fn func(row int, v T) error, opts ...Option) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return each(r, typ, newConfig(opts), func(row int, item reflect.Value) error {
+		return fn(row, item.Interface().(T))
+	})
+}
+
+func each(r io.Reader, typ reflect.Type, c config, fn func(row int, item reflect.Value) error) error {
+	reader := stdcsv.NewReader(r)
+	reader.Comma = c.comma
+	reader.FieldsPerRecord = -1
+	var header []string
+	row := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		row++
+		if err != nil {
+			return &RowError{Row: row, Err: err}
+		}
+		if c.header && header == nil {
+			header = append([]string(nil), record...)
+			continue
+		}
+		var src interface{} = record
+		if c.header {
+			m := make(map[string]string, len(header))
+			for i, name := range header {
+				if i < len(record) {
+					m[name] = record[i]
+				}
+			}
+			src = m
+		}
+		item := reflect.New(typ)
+		if err := forceset.Set(item.Interface(), src, c.options...); err != nil {
+			return rowError(row, err)
+		}
+		if err := fn(row, item.Elem()); err != nil {
+			return err
+		}
+	}
+}
+
+func rowError(row int, err error) *RowError {
+	e := &RowError{Row: row, Err: err}
+	var ce *forceset.ConversionError
+	if errors.As(err, &ce) {
+		e.Field = ce.Path
+	}
+	return e
+}
+
+// Encode writes src, a slice of structs or of pointers to structs, to w. The
+// header is made of the `csv` tag or field name of every exported field.
+func Encode(w io.Writer, src interface{}, opts ...Option) error {
+	c := newConfig(opts)
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return errors.New("csv encode from type(" + reflect.TypeOf(src).String() + ") failed: must be a slice")
+	}
+	typ := v.Type().Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return errors.New("csv encode from type(" + reflect.TypeOf(src).String() + ") failed: elements must be structs")
+	}
+	writer := stdcsv.NewWriter(w)
+	writer.Comma = c.comma
+	header := columns(typ, forceset.NewSetOption(c.options...).Tag)
+	if c.header {
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < v.Len(); i++ {
+		row := i + 1
+		if c.header {
+			row++
+		}
+		var record []string
+		if c.header {
+			m := map[string]string{}
+			if err := forceset.Set(&m, v.Index(i).Interface(), c.options...); err != nil {
+				return rowError(row, err)
+			}
+			record = make([]string, len(header))
+			for n, name := range header {
+				record[n] = m[name]
+			}
+		} else if err := forceset.Set(&record, v.Index(i).Interface(), c.options...); err != nil {
+			return rowError(row, err)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// columns returns the header names of typ in field order.
+func columns(typ reflect.Type, tag string) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		st := typ.Field(i)
+		if st.Anonymous {
+			ft := st.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				names = append(names, columns(ft, tag)...)
+				continue
+			}
+		}
+		if st.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(strings.Split(st.Tag.Get(tag), ";")[0], " ")[0]
+		if name == "" {
+			name = st.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
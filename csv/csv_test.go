@@ -0,0 +1,90 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cocotyty/forceset"
+)
+
+type Meta struct {
+	ID int64 `csv:"id"`
+}
+
+type Person struct {
+	*Meta
+	Name  string   `csv:"name"`
+	Age   int      `csv:"age"`
+	Tags  []string `csv:"tags;sep:|"`
+	Email string
+}
+
+const people = `id,name,age,tags,Email
+1,Peter,30,a|b,peter@example.com
+2,"Smith, Anna",41,,anna@example.com
+`
+
+func TestDecodeEncode(t *testing.T) {
+	var list []Person
+	err := Decode(strings.NewReader(people), &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Person{
+		{Meta: &Meta{ID: 1}, Name: "Peter", Age: 30, Tags: []string{"a", "b"}, Email: "peter@example.com"},
+		{Meta: &Meta{ID: 2}, Name: "Smith, Anna", Age: 41, Tags: []string{}, Email: "anna@example.com"},
+	}
+	if !reflect.DeepEqual(list, expected) {
+		t.Fatalf("%#v", list)
+	}
+	var buf bytes.Buffer
+	err = Encode(&buf, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != people {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestDecodeNoHeader(t *testing.T) {
+	var list []*Person
+	err := Decode(strings.NewReader("1;Peter;30\n"), &list, NoHeader, Comma(';'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != 1 || list[0].Name != "Peter" || list[0].Age != 30 {
+		t.Fatalf("%#v", list)
+	}
+}
+
+func TestEachRowError(t *testing.T) {
+	var names []string
+	err := Each(strings.NewReader(people+"3,Bob,old,,\n"), func(row int, p Person) error {
+		names = append(names, p.Name)
+		return nil
+	})
+	var re *RowError
+	if !errors.As(err, &re) || re.Row != 4 || re.Field != "Age" {
+		t.Fatal("expected error at row 4 Age got:", err)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) || err.Error() != "csv: row 4: Age: "+re.Err.(*forceset.ConversionError).Reason {
+		t.Fatal("expected wrapped cause got:", err)
+	}
+	if !reflect.DeepEqual(names, []string{"Peter", "Smith, Anna"}) {
+		t.Fatal(names)
+	}
+}
+
+func TestEncodeWithRegisterOptions(t *testing.T) {
+	var buf bytes.Buffer
+	err := Encode(&buf, []Person{{Name: "Peter"}}, SetOptions(forceset.RegisterEncoder("none", nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,132 @@
+package forceset
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// tryUseDecoder decodes a string or []byte src into dst. The format is taken
+// from the "decode:<name>" tag option, or else sniffed from the payload:
+// key=value pairs joined by & are "kv" and base64 or another byte encoding of
+// a payload is decoded first. Other payloads, JSON included, go to
+// SetOption.Decoder.
+func tryUseDecoder(dst, src reflect.Value, opt SetOption, tag string) error {
+	var data []byte
+	if src.Kind() == reflect.String {
+		data = []byte(src.String())
+	} else {
+		data = src.Bytes()
+	}
+	name, ok := tagOption(tag, "decode")
+	if !ok {
+		name = sniffDecoder(data, opt)
+	}
+	return decodeAs(dst, data, name, opt)
+}
+
+func decodeAs(dst reflect.Value, data []byte, name string, opt SetOption) error {
+	if b, ok := bytesOptions[name]; ok {
+		if enc := textEncodingOf(b); enc != nil {
+			raw, err := enc.DecodeString(string(bytes.TrimSpace(data)))
+			if err != nil {
+				return err
+			}
+			return decodeAs(dst, raw, sniffDecoder(raw, opt), opt)
+		}
+	}
+	if name == "" {
+		if opt.Decoder == nil {
+			return errors.New("no decoder for payload into type(" + dst.Type().String() + ")")
+		}
+		return opt.Decoder(data, dst.Addr().Interface())
+	}
+	if decode, ok := opt.Decoders[name]; ok {
+		return decode(data, dst.Addr().Interface())
+	}
+	if name == "kv" {
+		return decodeKV(dst, data, opt)
+	}
+	return errors.New("unknown decoder " + name)
+}
+
+// sniffDecoder is the decoder name for a payload without a decode tag option,
+// "" for JSON so that it goes to SetOption.Decoder.
+func sniffDecoder(data []byte, opt SetOption) string {
+	name := sniffFormat(data, opt)
+	if name == "json" {
+		return ""
+	}
+	return name
+}
+
+// sniffFormat returns "json", "kv", the name of a byte encoding or "" if the
+// format is unknown. Base64 is always tried, the encoding of BytesOption or
+// with DetectBytes all of them.
+func sniffFormat(data []byte, opt SetOption) string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return ""
+	}
+	switch data[0] {
+	case '{', '[':
+		return "json"
+	}
+	order := []BytesOption{Base64}
+	if opt.DetectBytes {
+		order = detectOrder
+	} else if textEncodingOf(opt.BytesOption) != nil && opt.BytesOption != Base64 {
+		order = []BytesOption{opt.BytesOption, Base64}
+	}
+	for _, b := range order {
+		if raw, err := textEncodingOf(b).DecodeString(string(data)); err == nil {
+			if inner := sniffFormat(raw, opt); inner != "" {
+				return bytesOptionName(b)
+			}
+		}
+	}
+	if isKV(string(data)) {
+		return "kv"
+	}
+	return ""
+}
+
+func isKV(s string) bool {
+	for _, pair := range strings.Split(s, "&") {
+		i := strings.IndexByte(pair, '=')
+		if i <= 0 || strings.ContainsAny(pair[:i], " \t\r\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeKV decodes URL encoded key=value pairs like "a=1&b=2".
+func decodeKV(dst reflect.Value, data []byte, opt SetOption) error {
+	values, err := url.ParseQuery(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return err
+	}
+	return forceSet(dst, valuesTree(values), opt, "")
+}
+
+// tagOption returns the value of a "key:value" option following the name in
+// a tag such as "name;key:value". It scans the tag without allocating, as it
+// runs for every option at every level of a conversion.
+func tagOption(tag, key string) (string, bool) {
+	i := strings.IndexByte(tag, ';')
+	for i >= 0 {
+		tag = tag[i+1:]
+		i = strings.IndexByte(tag, ';')
+		option := tag
+		if i >= 0 {
+			option = tag[:i]
+		}
+		if len(option) > len(key) && option[len(key)] == ':' && option[:len(key)] == key {
+			return option[len(key)+1:], true
+		}
+	}
+	return "", false
+}
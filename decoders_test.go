@@ -0,0 +1,99 @@
+package forceset
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+type blobConfig struct {
+	Plain  Address2 `json:"plain"`
+	Packed Address2 `json:"packed"`
+	Form   Address2 `json:"form"`
+	Custom Address2 `json:"custom;decode:lines"`
+}
+
+func decodeLines(data []byte, v interface{}) error {
+	m := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) == 2 {
+			m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return Set(v, m)
+}
+
+func TestSetDecodersByFormat(t *testing.T) {
+	src := map[string]interface{}{
+		"plain":  `{"Code":1,"Text":"json"}`,
+		"packed": base64.StdEncoding.EncodeToString([]byte(`Code=2&Text=packed`)),
+		"form":   []byte(`Code=3&Text=kv`),
+		"custom": "Code: 4\nText: lines",
+	}
+	var c blobConfig
+	err := Set(&c, src, RegisterDecoder("lines", decodeLines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := blobConfig{
+		Plain:  Address2{Code: 1, Text: "json"},
+		Packed: Address2{Code: 2, Text: "packed"},
+		Form:   Address2{Code: 3, Text: "kv"},
+		Custom: Address2{Code: 4, Text: "lines"},
+	}
+	if c != expected {
+		t.Fatalf("%#v", c)
+	}
+	err = Set(&c, src)
+	if err == nil || !strings.Contains(err.Error(), "unknown decoder lines") {
+		t.Fatal("expected unknown decoder error got:", err)
+	}
+}
+
+func TestSetDecoderOptions(t *testing.T) {
+	var called bool
+	var a Address2
+	err := Set(&a, `{"Code":1}`, func(opt *SetOption) {
+		opt.Decoder = func(data []byte, v interface{}) error {
+			called = true
+			return nil
+		}
+	})
+	if err != nil || !called {
+		t.Fatal("expected custom decoder to be called got:", called, err)
+	}
+	err = Set(&a, `{"Code":1}`, func(opt *SetOption) { opt.Decoder = nil })
+	if err == nil {
+		t.Fatal("expected error with decoding turned off got:", a)
+	}
+
+	var f struct {
+		A int `form:"a"`
+	}
+	if err := Set(&f, "a=5", func(opt *SetOption) { opt.Tag = "form" }); err != nil || f.A != 5 {
+		t.Fatal(f, err)
+	}
+}
+
+func TestTagOption(t *testing.T) {
+	for _, c := range []struct {
+		tag, key, value string
+		ok              bool
+	}{
+		{"name;sep:,;kv:=", "sep", ",", true},
+		{"name;sep:,;kv:=", "kv", "=", true},
+		{"name;separator:,", "sep", "", false},
+		{"sep:,", "sep", "", false},
+		{"name;sep:", "sep", "", true},
+		{"", "sep", "", false},
+	} {
+		value, ok := tagOption(c.tag, c.key)
+		if value != c.value || ok != c.ok {
+			t.Fatal(c.tag, c.key, value, ok)
+		}
+	}
+	if n := testing.AllocsPerRun(10, func() { tagOption("name;unit:bytes;locale:de", "locale") }); n != 0 {
+		t.Fatal("expected no allocations got:", n)
+	}
+}
@@ -0,0 +1,33 @@
+package forceset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// tryUseEncoder encodes i for a string or []byte destination. The encoder is
+// taken from the "encode:<name>" tag option, or else SetOption.Encoder is used
+// for struct, map and list sources that are not a fmt.Stringer. ok is false
+// when i is left to toString and toBytes.
+func tryUseEncoder(i interface{}, opt SetOption, tag string) (data []byte, ok bool, err error) {
+	if name, tagged := tagOption(tag, "encode"); tagged {
+		encode, found := opt.Encoders[name]
+		if !found {
+			return nil, true, errors.New("unknown encoder " + name)
+		}
+		data, err = encode(i)
+		return data, true, err
+	}
+	if opt.Encoder == nil {
+		return nil, false, nil
+	}
+	if _, stringer := i.(fmt.Stringer); stringer {
+		return nil, false, nil
+	}
+	if !isComposite(reflect.TypeOf(i), opt) {
+		return nil, false, nil
+	}
+	data, err = opt.Encoder(i)
+	return data, true, err
+}
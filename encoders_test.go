@@ -0,0 +1,47 @@
+package forceset
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSetEncodesComposites(t *testing.T) {
+	type Row struct {
+		Address string `db:"address"`
+		Raw     []byte `db:"raw"`
+		Tags    string `db:"tags;encode:csv"`
+		Created string `db:"created"`
+	}
+	created := time.Date(2020, 5, 19, 16, 20, 17, 0, time.UTC)
+	src := map[string]interface{}{
+		"address": Address2{Code: 1, Text: "t"},
+		"raw":     map[string]int{"a": 1},
+		"tags":    []string{"a", "b"},
+		"created": created,
+	}
+	var row Row
+	err := Set(&row, src, func(opt *SetOption) {
+		opt.Tag = "db"
+	}, RegisterEncoder("csv", func(v interface{}) ([]byte, error) {
+		var s string
+		for i, tag := range v.([]string) {
+			if i > 0 {
+				s += ","
+			}
+			s += tag
+		}
+		return []byte(s), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Address != `{"Code":1,"Text":"t"}` || string(row.Raw) != `{"a":1}` || row.Tags != "a,b" || row.Created != created.String() {
+		t.Fatalf("%#v", row)
+	}
+	var back Address2
+	err = Set(&back, json.RawMessage(row.Address))
+	if err != nil || back != (Address2{Code: 1, Text: "t"}) {
+		t.Fatal(back, err)
+	}
+}
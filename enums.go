@@ -0,0 +1,103 @@
+package forceset
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type enumInteger interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+	String() string
+}
+
+// RegisterEnum converts the names into values of T and values of T into their
+// names. A value with several names is written as the first one in order.
+func RegisterEnum[T comparable](names map[string]T) Option {
+	return func(opt *SetOption) {
+		table := make(map[string]interface{}, len(names))
+		for name, v := range names {
+			table[name] = v
+		}
+		opt.Enums[reflect.TypeOf((*T)(nil)).Elem()] = table
+	}
+}
+
+// DiscoverEnum registers the String names of the values first to last. It
+// panics if first is greater than last.
+func DiscoverEnum[T enumInteger](first, last T) Option {
+	if first > last {
+		panic("forceset: DiscoverEnum with first greater than last")
+	}
+	names := map[string]T{}
+	for v := first; ; v++ {
+		names[v.String()] = v
+		if v == last {
+			break
+		}
+	}
+	return RegisterEnum(names)
+}
+
+// StrictEnums rejects names and values that are not registered.
+func StrictEnums(opt *SetOption) {
+	opt.StrictEnums = true
+}
+
+// setEnum converts a name into a registered enum type. Other sources are
+// left to the usual conversion, in strict mode the result must be registered.
+func setEnum(value reflect.Value, i interface{}, opt SetOption) (bool, error) {
+	table, ok := opt.Enums[value.Type()]
+	if !ok {
+		return false, nil
+	}
+	var name string
+	switch o := i.(type) {
+	case string:
+		name = o
+	case []byte:
+		name = string(o)
+	default:
+		if !opt.StrictEnums || reflect.TypeOf(i) == value.Type() {
+			return false, nil
+		}
+		opt.StrictEnums = false
+		v := reflect.New(value.Type()).Elem()
+		if err := forceSet(v, i, opt, ""); err != nil {
+			return true, err
+		}
+		if _, ok := enumName(table, v.Interface()); !ok {
+			return true, invalidEnum(value.Type(), toString(i, opt), table)
+		}
+		value.Set(v)
+		return true, nil
+	}
+	if v, ok := table[name]; ok {
+		value.Set(reflect.ValueOf(v))
+		return true, nil
+	}
+	if opt.StrictEnums {
+		return true, invalidEnum(value.Type(), name, table)
+	}
+	return false, nil
+}
+
+func enumName(table map[string]interface{}, v interface{}) (string, bool) {
+	found := ""
+	for name, value := range table {
+		if value == v && (found == "" || name < found) {
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+func invalidEnum(t reflect.Type, name string, table map[string]interface{}) error {
+	names := make([]string, 0, len(table))
+	for n := range table {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return errors.New("invalid " + t.String() + " (" + name + "), valid: " + strings.Join(names, ", "))
+}
@@ -0,0 +1,66 @@
+package forceset
+
+import (
+	"strings"
+	"testing"
+)
+
+type status int
+
+const (
+	statusPending status = iota
+	statusActive
+	statusClosed
+)
+
+func (s status) String() string {
+	return [...]string{"PENDING", "ACTIVE", "CLOSED"}[s]
+}
+
+type color uint8
+
+func TestSetEnum(t *testing.T) {
+	var c struct {
+		Status status `json:"status"`
+		Color  color  `json:"color"`
+	}
+	opts := []Option{DiscoverEnum(statusPending, statusClosed), RegisterEnum(map[string]color{"red": 1, "green": 2})}
+	err := Set(&c, map[string]interface{}{"status": "ACTIVE", "color": []byte("green")}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != statusActive || c.Color != 2 {
+		t.Fatal(c)
+	}
+	var out map[string]string
+	if err := Set(&out, c, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if out["status"] != "ACTIVE" || out["color"] != "green" {
+		t.Fatal(out)
+	}
+
+	if err := Set(&c.Status, "2", opts...); err != nil || c.Status != statusClosed {
+		t.Fatal(c.Status, err)
+	}
+	strict := append(opts, StrictEnums)
+	err = Set(&c.Status, "DELETED", strict...)
+	if err == nil || !strings.Contains(err.Error(), "ACTIVE, CLOSED, PENDING") {
+		t.Fatal("expected error listing valid names got:", err)
+	}
+	if err := Set(&c.Color, 7, strict...); err == nil {
+		t.Fatal("expected error for unregistered value got:", c.Color)
+	}
+	if err := Set(&c.Color, 1, strict...); err != nil || c.Color != 1 {
+		t.Fatal(c.Color, err)
+	}
+}
+
+func TestDiscoverEnumRejectsReversedRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for first > last")
+		}
+	}()
+	DiscoverEnum(statusClosed, statusPending)
+}
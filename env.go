@@ -0,0 +1,174 @@
+package forceset
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FromEnv fills the struct dst points to from environment variables.
+//
+// A field is read from PREFIX_FIELD, nested struct fields from
+// PREFIX_FIELD_SUBFIELD and embedded structs share the prefix of their parent.
+// Names are the upper snake case of the field names, an `env:"NAME"` tag
+// replaces the name of its own segment. Slice fields are split on
+// SetOption.EnvSeparator. A field without variable takes its `default:"..."`
+// tag, and a field tagged `required:"true"` fails when it has neither.
+func FromEnv(dst interface{}, prefix string, opts ...Option) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("bind env into type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to struct")
+	}
+	b := envBinder{lookup: os.LookupEnv, defaults: true, required: true, opt: NewSetOption(opts...)}
+	_, err := b.bind(v.Elem(), strings.TrimSuffix(prefix, "_"), "")
+	return err
+}
+
+// envBinder sets struct fields from variables found by lookup.
+type envBinder struct {
+	lookup func(name string) (string, bool)
+	// defaults applies the default tag of fields without variable.
+	defaults bool
+	// required fails on required fields without variable or default.
+	required bool
+	// record, if not nil, is called with the path of every field set.
+	record func(path, source string)
+	opt    SetOption
+}
+
+// bind returns how many fields were set.
+func (b *envBinder) bind(dst reflect.Value, prefix, path string) (count int, err error) {
+	defer recoverPanic(&err)
+	opt := b.opt
+	dstType := dst.Type()
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		st := dstType.Field(i)
+		if st.PkgPath != "" && !st.Anonymous {
+			continue
+		}
+		name := prefix
+		fieldPath := path
+		if !st.Anonymous || st.Tag.Get("env") != "" {
+			name = envName(prefix, st)
+		}
+		if !st.Anonymous {
+			fieldPath = joinPath(path, st.Name)
+		}
+		source := "env:" + name
+		value, ok := b.lookup(name)
+		if !ok {
+			if isNestedStruct(st.Type, opt) {
+				target := field
+				for target.Kind() == reflect.Ptr && !target.IsNil() {
+					target = target.Elem()
+				}
+				if target.Kind() == reflect.Struct {
+					cnt, err := b.bind(target, name, fieldPath)
+					if err != nil {
+						return 0, err
+					}
+					count += cnt
+					continue
+				}
+				root, val := ptrValue(st.Type)
+				cnt, err := b.bind(val, name, fieldPath)
+				if err != nil {
+					return 0, err
+				}
+				if cnt != 0 {
+					field.Set(root.Elem())
+					count += cnt
+				}
+				continue
+			}
+			if b.defaults {
+				value, ok = st.Tag.Lookup("default")
+				source = "default"
+			}
+		}
+		if !ok {
+			if b.required && st.Tag.Get("required") == "true" {
+				return 0, &ConversionError{Path: fieldPath, Reason: "env " + name + " is required"}
+			}
+			continue
+		}
+		var src interface{} = value
+		if _, hasSep := tagOption(st.Tag.Get(opt.Tag), "sep"); isList(st.Type) && opt.EnvSeparator != "" && !hasSep {
+			src = strings.Split(value, opt.EnvSeparator)
+		}
+		if err := forceSet(field, src, opt, st.Tag.Get(opt.Tag)); err != nil {
+			ce, ok := err.(*ConversionError)
+			if !ok {
+				ce = &ConversionError{Reason: err.Error(), Err: err}
+			}
+			ce.Reason = source + ": " + ce.Reason
+			return 0, withPath(ce, fieldPath)
+		}
+		if b.record != nil {
+			b.record(fieldPath, source)
+		}
+		count++
+	}
+	return count, nil
+}
+
+func envName(prefix string, st reflect.StructField) string {
+	name := st.Tag.Get("env")
+	if name == "" {
+		name = toUpperSnake(st.Name)
+	}
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// isNestedStruct reports whether typ is bound field by field rather than as a whole.
+func isNestedStruct(typ reflect.Type, opt SetOption) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	if _, ok := opt.Mappers[MapperType{typ, reflect.TypeOf("")}]; ok {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isList(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return typ.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// toUpperSnake turns "HTTPServerPort" into "HTTP_SERVER_PORT".
+func toUpperSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package forceset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envConfig struct {
+	Server struct {
+		Port    int
+		Host    string `default:"localhost"`
+		Timeout time.Duration
+	}
+	HTTPProxy *struct {
+		URL string
+	}
+	Tags  []int
+	Token string `env:"SECRET"`
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("APP_SERVER_PORT", "8080")
+	t.Setenv("APP_SERVER_TIMEOUT", "3000000000")
+	t.Setenv("APP_TAGS", "1,2,3")
+	t.Setenv("APP_SECRET", "s3cr3t")
+	var c envConfig
+	err := FromEnv(&c, "APP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != 8080 || c.Server.Host != "localhost" || c.Server.Timeout != 3*time.Second {
+		t.Fatalf("%#v", c.Server)
+	}
+	if !reflect.DeepEqual(c.Tags, []int{1, 2, 3}) || c.Token != "s3cr3t" {
+		t.Fatalf("%#v", c)
+	}
+	if c.HTTPProxy != nil {
+		t.Fatal("expected nil got:", c.HTTPProxy)
+	}
+	t.Setenv("APP_HTTP_PROXY_URL", "http://proxy")
+	t.Setenv("APP_TAGS", "1;x")
+	err = FromEnv(&c, "APP_", EnvSeparator(";"))
+	var ce *ConversionError
+	if !errors.As(err, &ce) || ce.Path != "Tags[1]" {
+		t.Fatal("expected error at Tags[1] got:", err)
+	}
+	if c.HTTPProxy == nil || c.HTTPProxy.URL != "http://proxy" {
+		t.Fatalf("%#v", c.HTTPProxy)
+	}
+}
+
+func TestFromEnvRequired(t *testing.T) {
+	var c struct {
+		Port int `required:"true"`
+	}
+	err := FromEnv(&c, "APP")
+	if err == nil {
+		t.Fatal("expected required error")
+	}
+}
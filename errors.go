@@ -0,0 +1,83 @@
+package forceset
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// ConversionError reports a failed conversion together with the path of the
+// destination element that failed, such as "Items[2].Name".
+type ConversionError struct {
+	Path   string
+	Reason string
+	Err    error
+}
+
+func (e *ConversionError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+	return e.Path + ": " + e.Reason
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// withPath prefixes the path of err with elem, a field name or an "[index]".
+func withPath(err error, elem string) error {
+	if err == nil {
+		return nil
+	}
+	ce, ok := err.(*ConversionError)
+	if !ok {
+		ce = &ConversionError{Reason: err.Error(), Err: err}
+	}
+	ce.Path = joinPath(elem, ce.Path)
+	return ce
+}
+
+func joinPath(parent, child string) string {
+	switch {
+	case child == "":
+		return parent
+	case parent == "" || strings.HasPrefix(child, "["):
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// recoverPanic turns a panic raised during a conversion into a ConversionError.
+// It must be deferred directly.
+func recoverPanic(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	reason := fmt.Sprint(r)
+	if site := panicSite(); site != "" {
+		reason += " (in " + site + ")"
+	}
+	*err = &ConversionError{Reason: reason}
+}
+
+// panicSite returns the innermost function of this package on the panicking stack.
+func panicSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	panicked := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			panicked = true
+		} else if panicked && strings.HasPrefix(frame.Function, pkgPath+".") {
+			return frame.Function[strings.LastIndex(pkgPath, "/")+1:]
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+const pkgPath = "github.com/cocotyty/forceset"
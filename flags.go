@@ -0,0 +1,170 @@
+package forceset
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+)
+
+// RegisterFlags defines a flag on fs for every field of the struct dst points to.
+//
+// A flag is named by its `flag:"name"` tag or the lower kebab case of the field
+// name, nested struct fields are joined with a dot as in "server.port" and
+// embedded structs share the name of their parent. The `usage:"..."` tag is the
+// help text. The default shown in it is the current field value, or else the
+// `default:"..."` tag, which apply sets into the field when its flag is not
+// given and the field is still zero. A `flag:"-"` tag skips the field.
+//
+// The returned apply func must be called after fs.Parse, it converts only the
+// flags given on the command line into their fields, so values loaded before
+// from files or env are kept. A repeated flag fills a slice field with all its
+// values, any other field with the last one.
+func RegisterFlags(fs *flag.FlagSet, dst interface{}, opts ...Option) (apply func() error, err error) {
+	defer recoverPanic(&err)
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("register flags for type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to struct")
+	}
+	opt := NewSetOption(opts...)
+	flags := map[*fieldFlag]bool{}
+	registerFlags(fs, v.Elem(), nil, "", "", opt, flags, true)
+	return func() error {
+		if err := applyFlags(fs, v.Elem(), flags, opt, nil); err != nil {
+			return err
+		}
+		return applyFlagDefaults(v.Elem(), flags, opt)
+	}, nil
+}
+
+// applyFlags converts the flags given on the command line into the fields of root.
+func applyFlags(fs *flag.FlagSet, root reflect.Value, flags map[*fieldFlag]bool, opt SetOption, record func(path, source string)) (err error) {
+	defer recoverPanic(&err)
+	fs.Visit(func(f *flag.Flag) {
+		ff, ok := f.Value.(*fieldFlag)
+		if !ok || !flags[ff] || err != nil {
+			return
+		}
+		field, _ := fieldByIndex(root, ff.index, true)
+		var src interface{} = ff.values
+		if !isList(field.Type()) {
+			src = ff.values[len(ff.values)-1]
+		}
+		if e := forceSet(field, src, opt, ff.tag); e != nil {
+			ce, ok := e.(*ConversionError)
+			if !ok {
+				ce = &ConversionError{Reason: e.Error(), Err: e}
+			}
+			ce.Reason = "flag -" + f.Name + ": " + ce.Reason
+			err = withPath(ce, ff.path)
+			return
+		}
+		if record != nil {
+			record(ff.path, "flag:"+f.Name)
+		}
+	})
+	return err
+}
+
+// applyFlagDefaults sets the default tag of every flag that was not given into
+// its field if the field is still zero.
+func applyFlagDefaults(root reflect.Value, flags map[*fieldFlag]bool, opt SetOption) (err error) {
+	defer recoverPanic(&err)
+	for ff := range flags {
+		if !ff.setDefault || len(ff.values) != 0 {
+			continue
+		}
+		field, ok := fieldByIndex(root, ff.index, false)
+		if ok && !field.IsZero() {
+			continue
+		}
+		field, _ = fieldByIndex(root, ff.index, true)
+		if err := forceSet(field, ff.def, opt, ff.tag); err != nil {
+			return withPath(err, ff.path)
+		}
+	}
+	return nil
+}
+
+// registerFlags defines the flags of dst, showing default tags only if
+// defaults is true because the caller applies them.
+func registerFlags(fs *flag.FlagSet, dst reflect.Value, index []int, prefix, path string, opt SetOption, flags map[*fieldFlag]bool, defaults bool) {
+	dstType := dst.Type()
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Field(i)
+		st := dstType.Field(i)
+		name := st.Tag.Get("flag")
+		if name == "-" || (st.PkgPath != "" && !st.Anonymous) {
+			continue
+		}
+		if name == "" && !st.Anonymous {
+			name = strings.ReplaceAll(strings.ToLower(toUpperSnake(st.Name)), "_", "-")
+		}
+		if prefix != "" && name != "" {
+			name = prefix + "." + name
+		} else if name == "" {
+			name = prefix
+		}
+		fieldPath := joinPath(path, st.Name)
+		if st.Anonymous {
+			fieldPath = path
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if st.Type.Kind() == reflect.Struct && isNestedStruct(st.Type, opt) {
+			registerFlags(fs, field, fieldIndex, name, fieldPath, opt, flags, defaults)
+			continue
+		}
+		if st.Anonymous && st.Type.Kind() == reflect.Ptr && isNestedStruct(st.Type.Elem(), opt) {
+			if st.PkgPath != "" {
+				continue
+			}
+			// allocated when one of its flags is given
+			if field.IsNil() {
+				field = reflect.New(st.Type.Elem())
+			}
+			registerFlags(fs, field.Elem(), fieldIndex, name, fieldPath, opt, flags, defaults)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		ff := &fieldFlag{index: fieldIndex, path: fieldPath, tag: st.Tag.Get(opt.Tag)}
+		ff.isBool = field.Kind() == reflect.Bool
+		if !field.IsZero() {
+			ff.def = toString(field.Interface(), opt)
+		} else if def, ok := st.Tag.Lookup("default"); ok && defaults {
+			ff.def = def
+			ff.setDefault = true
+		}
+		flags[ff] = true
+		fs.Var(ff, name, st.Tag.Get("usage"))
+	}
+}
+
+// fieldFlag is the flag.Value of a struct field, it only records what was given.
+type fieldFlag struct {
+	index []int
+	path  string
+	tag   string
+	def   string
+	// setDefault is true when def is the default tag
+	setDefault bool
+	isBool     bool
+	values     []string
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.def
+}
+
+func (f *fieldFlag) Set(s string) error {
+	f.values = append(f.values, s)
+	return nil
+}
+
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.isBool
+}
@@ -0,0 +1,102 @@
+package forceset
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type flagConfig struct {
+	Server struct {
+		Port int    `usage:"listen port" default:"8080"`
+		Host string `flag:"bind"`
+	}
+	Verbose  bool
+	Peers    []string
+	LogLevel string
+	Secret   string `flag:"-"`
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c := flagConfig{LogLevel: "info"}
+	c.Server.Port = 80
+	apply, err := RegisterFlags(fs, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := fs.Lookup("server.port"); f == nil || f.Usage != "listen port" || f.DefValue != "80" {
+		t.Fatalf("%#v", f)
+	}
+	if f := fs.Lookup("log-level"); f == nil || f.DefValue != "info" {
+		t.Fatalf("%#v", f)
+	}
+	if fs.Lookup("secret") != nil {
+		t.Fatal("expected secret to be skipped")
+	}
+	err = fs.Parse(strings.Fields("-server.bind 0.0.0.0 -verbose -peers a -peers b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != 80 || c.Server.Host != "0.0.0.0" || !c.Verbose || c.LogLevel != "info" {
+		t.Fatalf("%#v", c)
+	}
+	if !reflect.DeepEqual(c.Peers, []string{"a", "b"}) {
+		t.Fatal(c.Peers)
+	}
+
+	var zero flagConfig
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	apply, _ = RegisterFlags(fs, &zero)
+	if f := fs.Lookup("server.port"); f.DefValue != "8080" {
+		t.Fatalf("%#v", f)
+	}
+	_ = fs.Parse(nil)
+	if err := apply(); err != nil || zero.Server.Port != 8080 {
+		t.Fatal(zero.Server.Port, err)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	apply, _ = RegisterFlags(fs, &c)
+	_ = fs.Parse([]string{"-server.port", "http"})
+	if err := apply(); err == nil || !strings.Contains(err.Error(), "Server.Port") {
+		t.Fatal("expected error at Server.Port got:", err)
+	}
+}
+
+type Logging struct {
+	Level string
+}
+
+func TestRegisterFlagsEmbeddedPointer(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var c struct {
+		*Logging
+		Name string
+	}
+	apply, err := RegisterFlags(fs, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "" {
+			t.Fatal("registered a flag without name")
+		}
+	})
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := apply(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Logging == nil || c.Level != "debug" {
+		t.Fatal(c.Logging)
+	}
+}
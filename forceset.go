@@ -1,36 +1,96 @@
 package forceset
 
 import (
-	"bytes"
-	"encoding/base64"
-	"encoding/binary"
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// Set converts src into the value dst points to. It never panics: a panic raised
+// while converting is returned as a *ConversionError.
 func Set(dst interface{}, src interface{}, opts ...Option) error {
-	return ForceSet(reflect.ValueOf(dst).Elem(), src, opts...)
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("force set into non-pointer or nil destination")
+	}
+	return ForceSet(v.Elem(), src, opts...)
 }
 
+// ForceSet converts i into value, which must be settable or a non-nil pointer.
 func ForceSet(value reflect.Value, i interface{}, opts ...Option) error {
+	if !value.IsValid() {
+		return errors.New("force set into invalid destination")
+	}
+	opt := NewSetOption(opts...)
+	if opt.Atomic {
+		return forceSetAtomic(value, i, opt, "")
+	}
+	return forceSet(value, i, opt, "")
+}
+
+// SetField converts i into value as the struct field name tagged tag, so
+// tag options such as "sep" and "unit" apply. Error paths start at name.
+func SetField(value reflect.Value, name, tag string, i interface{}, opts ...Option) (err error) {
+	if !value.IsValid() {
+		return errors.New("force set into invalid destination")
+	}
+	opt := NewSetOption(opts...)
+	if opt.Atomic {
+		err = forceSetAtomic(value, i, opt, tag)
+	} else {
+		err = forceSet(value, i, opt, tag)
+	}
+	return withPath(err, name)
+}
+
+// NewSetOption returns the default options with opts applied, as Set uses them.
+func NewSetOption(opts ...Option) SetOption {
 	var opt SetOption
 	opt.Mappers = map[MapperType]Mapper{}
 	opt.Tag = "json"
 	opt.Decoder = json.Unmarshal
-	if len(opts) != 0 {
-		for _, fn := range opts {
-			fn(&opt)
-		}
+	opt.Decoders = map[string]func([]byte, interface{}) error{
+		"json": json.Unmarshal,
 	}
-	return forceSet(value, i, opt, "")
+	opt.Encoder = json.Marshal
+	opt.Encoders = map[string]func(interface{}) ([]byte, error){
+		"json": json.Marshal,
+	}
+	opt.MaxSliceLength = DefaultMaxSliceLength
+	opt.EnvSeparator = ","
+	opt.Enums = map[reflect.Type]map[string]interface{}{}
+	opt.Types = map[reflect.Type]map[string]reflect.Type{}
+	opt.Discriminator = "type"
+	for _, fn := range opts {
+		fn(&opt)
+	}
+	return opt
 }
 
-func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) error {
+func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) (err error) {
+	defer recoverPanic(&err)
+	if values, ok := i.(Values); ok {
+		i = values.pick(value.Type(), tag)
+	}
+	if valuer, ok := i.(driver.Valuer); ok && reflect.TypeOf(i) != indirectType(value.Type()) {
+		if i, err = valuer.Value(); err != nil {
+			return err
+		}
+		if i == nil {
+			return setNull(value)
+		}
+	}
 	if i == nil {
+		if isScanner(value) {
+			return value.Addr().Interface().(sql.Scanner).Scan(nil)
+		}
 		return nil
 	}
 	for value.Kind() == reflect.Ptr {
@@ -39,13 +99,87 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 		}
 		value = value.Elem()
 	}
+	if value.Kind() == reflect.Struct {
+		opt.discriminator = ""
+	}
+	if key, ok := tagOption(tag, "type"); ok {
+		opt.discriminator = key
+	}
+	if ok, err := setPolymorphic(value, i, opt); ok {
+		return err
+	}
+	if value.Kind() == reflect.Interface && !value.IsNil() {
+		// convert into the concrete value the interface already holds
+		concrete := reflect.New(value.Elem().Type()).Elem()
+		concrete.Set(value.Elem())
+		if err := forceSet(concrete, i, opt, tag); err != nil {
+			return err
+		}
+		value.Set(concrete)
+		return nil
+	}
 	var bErr error
 	iv := reflect.ValueOf(i)
 	if m, ok := opt.Mappers[MapperType{value.Type(), iv.Type()}]; ok {
 		return m(value, iv, tag)
 	}
+	if isScanner(value) && iv.Type() != value.Type() {
+		if ok, err := tryScan(value, i, opt, tag); ok {
+			return err
+		}
+	}
+	if opt, err = bytesByTag(opt, tag); err != nil {
+		return err
+	}
+	if ok, err := setNetType(value, i); ok {
+		return err
+	}
+	if ok, err := setEnum(value, i, opt); ok {
+		return err
+	}
+	if ok, err := setBigNumber(value, i); ok {
+		return err
+	}
+	if ok, err := setUnit(value, i, opt, tag); ok {
+		return err
+	}
+	if i, err = localizeNumber(value, i, opt, tag); err != nil {
+		return err
+	}
+	if value.Kind() == reflect.String || value.Kind() == reflect.Interface && value.IsNil() {
+		if s, ok, err := formatUnit(i, tag); ok {
+			if err != nil {
+				return err
+			}
+			value.Set(reflect.ValueOf(s).Convert(value.Type()))
+			return nil
+		}
+	}
+	if values, ok := i.(url.Values); ok && !iv.Type().ConvertibleTo(value.Type()) {
+		switch value.Kind() {
+		case reflect.Struct, reflect.Map:
+			return forceSet(value, valuesTree(values), opt, tag)
+		}
+	}
+	if src, ok := splitByTag(value, i, tag); ok {
+		return forceSet(value, src, opt, "")
+	}
 	switch value.Kind() {
 	case reflect.String:
+		if s, ok, err := joinByTag(i, opt, tag); ok {
+			if err != nil {
+				return err
+			}
+			value.SetString(s)
+			return nil
+		}
+		if data, ok, err := tryUseEncoder(i, opt, tag); ok {
+			if err != nil {
+				return err
+			}
+			value.SetString(string(data))
+			return nil
+		}
 		value.SetString(toString(i, opt))
 		return nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -86,11 +220,21 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 
 	case reflect.Slice:
 		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if data, ok, err := tryUseEncoder(i, opt, tag); ok {
+				if err != nil {
+					return err
+				}
+				value.SetBytes(data)
+				return nil
+			}
 			data, err := toBytes(i, opt)
 			if err == nil {
 				value.SetBytes(data)
 				return nil
 			}
+			if _, ok := i.(string); ok {
+				return err
+			}
 		}
 	}
 	if iv.Type() == value.Type() {
@@ -134,7 +278,7 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 				elm := iv.Index(n)
 				err := forceSet(proxyValue.Index(n), elm.Interface(), opt, "")
 				if err != nil {
-					return err
+					return withPath(err, "["+strconv.Itoa(n)+"]")
 				}
 			}
 			value.Set(proxyValue)
@@ -173,6 +317,10 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 		case reflect.Map:
 			_, err := map2Struct(value, iv, opt)
 			return err
+		case reflect.Slice, reflect.Array:
+			if iv.Type().Elem().Kind() != reflect.Uint8 {
+				return slice2struct(value, iv, opt)
+			}
 		}
 	case reflect.Map:
 		for iv.Kind() == reflect.Ptr {
@@ -197,21 +345,27 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 		}
 		switch iv.Kind() {
 		case reflect.Struct:
+			if value.IsNil() {
+				value.Set(reflect.MakeMap(value.Type()))
+			}
 			return struct2map(value, iv, opt)
 		case reflect.Map:
+			if value.IsNil() {
+				value.Set(reflect.MakeMap(value.Type()))
+			}
 			return map2map(value, iv, opt)
 
 			//case reflect.String:
 			//
 		}
 	}
-	if opt.Decoder != nil {
+	if _, tagged := tagOption(tag, "decode"); opt.Decoder != nil || tagged {
 		switch iv.Kind() {
 		case reflect.String:
-			return tryUseDecoder(value, iv, opt)
+			return tryUseDecoder(value, iv, opt, tag)
 		case reflect.Slice:
 			if iv.Type().Elem().Kind() == reflect.Uint8 {
-				return tryUseDecoder(value, iv, opt)
+				return tryUseDecoder(value, iv, opt, tag)
 			}
 		}
 	}
@@ -220,16 +374,6 @@ func forceSet(value reflect.Value, i interface{}, opt SetOption, tag string) err
 
 var empty = reflect.Value{}
 
-func tryUseDecoder(dst, src reflect.Value, opt SetOption) error {
-	if src.Kind() == reflect.String {
-		data := src.Convert(reflect.ValueOf(``).Type()).Interface().(string)
-		return opt.Decoder([]byte(data), dst.Addr().Interface())
-
-	}
-	data := src.Convert(reflect.ValueOf([]byte(``)).Type()).Interface().([]byte)
-	return opt.Decoder(data, dst.Addr().Interface())
-}
-
 func struct2Struct(dst, src reflect.Value, opt SetOption) error {
 	num := dst.NumField()
 	for i := 0; i < num; i++ {
@@ -255,7 +399,7 @@ func struct2Struct(dst, src reflect.Value, opt SetOption) error {
 		}
 		err := setPtr(df, sf, opt)
 		if err != nil {
-			return err
+			return withPath(err, dt.Name)
 		}
 	}
 	return nil
@@ -287,6 +431,16 @@ func map2Struct(dst, src reflect.Value, opt SetOption) (count int, err error) {
 		sf := dst.Field(i)
 		st := dstType.Field(i)
 		fieldValue := sf
+		if st.Anonymous && st.Type.Kind() == reflect.Struct {
+			cnt, err := map2Struct(sf, src, opt)
+			if err != nil {
+				return 0, err
+			}
+			if cnt != 0 {
+				count++
+			}
+			continue
+		}
 		if st.Anonymous {
 			typ := st.Type
 			var tempValue reflect.Value
@@ -320,13 +474,8 @@ func map2Struct(dst, src reflect.Value, opt SetOption) (count int, err error) {
 			continue
 		}
 		tag := st.Tag.Get(opt.Tag)
-		names := strings.Split(strings.Split(tag, ";")[0], " ")
-		if len(names) == 1 && names[0] == "" {
-			names = []string{st.Name}
-		}
-
 		var value reflect.Value
-		for _, name := range names {
+		for _, name := range fieldNames(st, opt) {
 			value = src.MapIndex(reflect.ValueOf(name))
 			if value != empty {
 				break
@@ -337,13 +486,22 @@ func map2Struct(dst, src reflect.Value, opt SetOption) (count int, err error) {
 		}
 		err := forceSet(fieldValue, value.Interface(), opt, tag)
 		if err != nil {
-			return 0, err
+			return 0, withPath(err, st.Name)
 		}
 		count++
 	}
 	return count, nil
 }
 
+// fieldNames returns the map keys a struct field is looked up by.
+func fieldNames(st reflect.StructField, opt SetOption) []string {
+	names := strings.Split(strings.Split(st.Tag.Get(opt.Tag), ";")[0], " ")
+	if len(names) == 1 && names[0] == "" {
+		names = []string{st.Name}
+	}
+	return names
+}
+
 // dst map
 // src struct
 func struct2map(dst, src reflect.Value, opt SetOption) error {
@@ -356,11 +514,11 @@ func struct2map(dst, src reflect.Value, opt SetOption) error {
 		structField := srcType.Field(i)
 		if structField.Anonymous {
 			f := field
-			for f.Kind() == reflect.Ptr {
-				if f.IsNil() {
-					break
-				}
-				f = field.Elem()
+			for f.Kind() == reflect.Ptr && !f.IsNil() {
+				f = f.Elem()
+			}
+			if f.Kind() != reflect.Struct {
+				continue
 			}
 			err := struct2map(dst, f, opt)
 			if err != nil {
@@ -372,10 +530,18 @@ func struct2map(dst, src reflect.Value, opt SetOption) error {
 			continue
 		}
 		var tag = structField.Tag.Get(opt.Tag)
+		key, ok := tagOption(tag, "type")
+		if !ok {
+			key = opt.Discriminator
+		}
+		fieldValue, err := withDiscriminator(field, key, opt)
+		if err != nil {
+			return withPath(err, structField.Name)
+		}
 		root, val := ptrValue(valueType)
-		err := forceSet(val, field.Interface(), opt, tag)
+		err = forceSet(val, fieldValue, opt, tag)
 		if err != nil {
-			return err
+			return withPath(err, structField.Name)
 		}
 		keyName := strings.Split(strings.Split(tag, ";")[0], " ")[0]
 		if keyName == "" {
@@ -384,7 +550,7 @@ func struct2map(dst, src reflect.Value, opt SetOption) error {
 		k := reflect.New(keyType)
 		err = forceSet(k.Elem(), keyName, opt, tag)
 		if err != nil {
-			return err
+			return withPath(err, structField.Name)
 		}
 		dst.SetMapIndex(k.Elem(), root.Elem())
 	}
@@ -415,12 +581,12 @@ func map2map(dst, src reflect.Value, opt SetOption) error {
 		k, kr := ptrValue(keyType)
 		err := forceSet(kr, key.Interface(), opt, "")
 		if err != nil {
-			return err
+			return withPath(err, "["+toString(key.Interface(), opt)+"]")
 		}
 		v, vr := ptrValue(valueType)
 		err = forceSet(vr, val.Interface(), opt, "")
 		if err != nil {
-			return err
+			return withPath(err, "["+toString(key.Interface(), opt)+"]")
 		}
 		dst.SetMapIndex(k.Elem(), v.Elem())
 	}
@@ -431,36 +597,25 @@ func map2map(dst, src reflect.Value, opt SetOption) error {
 // src struct
 func struct2slice(dst, src reflect.Value, opt SetOption) error {
 	itemType := dst.Type().Elem()
-	n := src.NumField()
-	slice := reflect.MakeSlice(dst.Type(), 0, n)
-	srcType := src.Type()
-	for i := 0; i < n; i++ {
-		field := src.Field(i)
-		structField := srcType.Field(i)
-		if structField.Anonymous {
-			for field.Kind() == reflect.Ptr {
-				if field.IsNil() {
-					break
-				}
-				field = field.Elem()
-			}
-			if field.Type().Kind() == reflect.Struct {
-				err := struct2slice(dst, field, opt)
-				if err != nil {
-					return err
-				}
-			}
-			continue
+	fields := positionalFields(src.Type())
+	var n int
+	for _, f := range fields {
+		if f.pos >= n {
+			n = f.pos + 1
 		}
-		if structField.PkgPath != "" {
+	}
+	slice := reflect.MakeSlice(dst.Type(), n, n)
+	for _, f := range fields {
+		field, ok := fieldByIndex(src, f.index, false)
+		if !ok {
 			continue
 		}
 		value := reflect.New(itemType)
 		err := setPtr(value.Elem(), field, opt)
 		if err != nil {
-			return nil
+			return withPath(err, f.name)
 		}
-		slice = reflect.Append(slice, value.Elem())
+		slice.Index(f.pos).Set(value.Elem())
 	}
 	dst.Set(slice)
 	return nil
@@ -478,41 +633,89 @@ func map2slice(dst, src reflect.Value, opt SetOption) error {
 	if len(keys) == 0 {
 		return nil
 	}
+	items := make([]sliceItem, 0, len(keys))
 	var max int
 	for _, key := range keys {
-		var k int
-		err := forceSet(reflect.ValueOf(&k), key.Interface(), opt, "")
+		k, err := sliceIndex(key, opt)
 		if err != nil {
 			return err
 		}
 		if k > max {
 			max = k
 		}
+		items = append(items, sliceItem{index: k, value: src.MapIndex(key)})
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].index < items[b].index })
+	distinct := len(items)
+	for n := 1; n < len(items); n++ {
+		if items[n].index == items[n-1].index {
+			distinct--
+		}
 	}
 
-	var l = max + 1
-	slice := reflect.MakeSlice(dst.Type(), l, l)
+	var l int
+	switch opt.SparsePolicy {
+	case SparseCompact:
+		l = distinct
+	case SparseReject:
+		if max >= distinct {
+			return fmt.Errorf("cannot convert map to slice: keys are sparse, %d entries but largest index is %d", distinct, max)
+		}
+		l = max + 1
+	default:
+		if max == maxInt {
+			return errors.New("cannot convert map to slice: index " + strconv.Itoa(max) + " out of range")
+		}
+		l = max + 1
+	}
+	if opt.MaxSliceLength > 0 && l > opt.MaxSliceLength {
+		return fmt.Errorf("cannot convert map to slice: length %d exceeds limit %d", l, opt.MaxSliceLength)
+	}
+	if opt.MaxSparseRatio > 0 && float64(l) > opt.MaxSparseRatio*float64(distinct) {
+		return fmt.Errorf("cannot convert map to slice: length %d for %d entries exceeds sparse ratio %g", l, distinct, opt.MaxSparseRatio)
+	}
 
-	iter := src.MapRange()
-	for iter.Next() {
-		key := iter.Key()
-		val := iter.Value()
-		var k int
-		err := forceSet(reflect.ValueOf(&k), key.Interface(), opt, "")
-		if err != nil {
-			return err
+	slice := reflect.MakeSlice(dst.Type(), l, l)
+	pos := -1
+	for n, item := range items {
+		if n == 0 || item.index != items[n-1].index {
+			pos++
 		}
 		v, vr := ptrValue(valueType)
-		err = forceSet(vr, val.Interface(), opt, "")
+		err := forceSet(vr, item.value.Interface(), opt, "")
 		if err != nil {
-			return err
+			return withPath(err, "["+strconv.Itoa(item.index)+"]")
+		}
+		if opt.SparsePolicy == SparseCompact {
+			slice.Index(pos).Set(v.Elem())
+			continue
 		}
-		slice.Index(k).Set(v.Elem())
+		slice.Index(item.index).Set(v.Elem())
 	}
 	dst.Set(slice)
 	return nil
 }
 
+const maxInt = int(^uint(0) >> 1)
+
+type sliceItem struct {
+	index int
+	value reflect.Value
+}
+
+// sliceIndex converts a map key into a non-negative slice index.
+func sliceIndex(key reflect.Value, opt SetOption) (int, error) {
+	var k int
+	err := forceSet(reflect.ValueOf(&k), key.Interface(), opt, "")
+	if err != nil {
+		return 0, errors.New("cannot convert map to slice: key (" + toString(key.Interface(), opt) + ") is not a valid index")
+	}
+	if k < 0 {
+		return 0, errors.New("cannot convert map to slice: key (" + toString(key.Interface(), opt) + ") is a negative index")
+	}
+	return k, nil
+}
+
 // dst []pair
 // src map[key]val
 func map2slice2(dst, src reflect.Value, opt SetOption) error {
@@ -537,52 +740,57 @@ func map2slice2(dst, src reflect.Value, opt SetOption) error {
 	}
 	l := src.Len()
 	slice := reflect.MakeSlice(dst.Type(), l, l)
-	iter := src.MapRange()
-	var i int
-	for iter.Next() {
-		k := iter.Key()
-		v := iter.Value()
+	keys := src.MapKeys()
+	sortKeys(keys)
+	for i, k := range keys {
+		v := src.MapIndex(k)
 
 		root, val := ptrValue(elmType)
 		err := forceSet(val.Field(0), k.Interface(), opt, "")
 		if err != nil {
-			return err
+			return withPath(err, "["+strconv.Itoa(i)+"]."+kf.Name)
 		}
 		err = forceSet(val.Field(1), v.Interface(), opt, "")
 		if err != nil {
-			return err
+			return withPath(err, "["+strconv.Itoa(i)+"]."+vf.Name)
 		}
 		slice.Index(i).Set(root.Elem())
-		i++
 	}
 	dst.Set(slice)
 	return nil
 }
 
+// sortKeys orders map keys so pairs come out in a stable order.
+func sortKeys(keys []reflect.Value) {
+	sort.Slice(keys, func(a, b int) bool {
+		ka, kb := keys[a], keys[b]
+		switch ka.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return ka.Int() < kb.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return ka.Uint() < kb.Uint()
+		case reflect.Float32, reflect.Float64:
+			return ka.Float() < kb.Float()
+		case reflect.String:
+			return ka.String() < kb.String()
+		}
+		return fmt.Sprint(ka.Interface()) < fmt.Sprint(kb.Interface())
+	})
+}
+
 func toBytes(i interface{}, opt SetOption) ([]byte, error) {
 	switch o := i.(type) {
 	case []byte:
 		return o, nil
 	case string:
-		switch opt.BytesOption {
-		case Base64:
-			data, err := base64.StdEncoding.DecodeString(o)
-			if err != nil {
-				return nil, err
-			}
-			return data, nil
-		default:
-			return []byte(o), nil
-		}
+		return decodeBytes(o, opt)
 	}
 	if opt.BytesOption == AsString {
 		return []byte(toString(i, opt)), nil
 	}
 	switch i.(type) {
 	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, uintptr:
-		buffer := bytes.NewBuffer(nil)
-		_ = binary.Write(buffer, binary.LittleEndian, i)
-		return buffer.Bytes(), nil
+		return encodeBinary(i, opt), nil
 	}
 	return nil, errors.New("type (" + reflect.TypeOf(i).String() + ") to []byte invalid")
 }
@@ -629,6 +837,9 @@ func toBool(i interface{}, opt SetOption) (bool, error) {
 			return true, nil
 		}
 	case []byte:
+		if opt.BytesOption == Binary {
+			return decodeBinaryBool(o, opt)
+		}
 		return strconv.ParseBool(string(o))
 	}
 	return false, errors.New("type (" + reflect.TypeOf(i).String() + ") to bool invalid")
@@ -660,13 +871,13 @@ func toInt(i interface{}, opt SetOption) (int64, error) {
 	case []byte:
 		switch opt.BytesOption {
 		case AsString:
-			return strconv.ParseInt(string(o), 10, 0)
+			return parseInt(string(o), opt)
 		case Binary:
-			i64, _ := binary.Varint(o)
-			return i64, nil
+			u, err := decodeBinary(o, true, opt)
+			return int64(u), err
 		}
 	case string:
-		return strconv.ParseInt(string(o), 10, 0)
+		return parseInt(string(o), opt)
 	case bool:
 		if o {
 			return 1, nil
@@ -708,6 +919,9 @@ func toFloat(i interface{}, opt SetOption) (float64, error) {
 		return float64(o), nil
 
 	case []byte:
+		if opt.BytesOption == Binary {
+			return decodeBinaryFloat(o, opt)
+		}
 		return strconv.ParseFloat(string(o), 64)
 
 	case string:
@@ -756,13 +970,12 @@ func toUint(i interface{}, opt SetOption) (uint64, error) {
 	case []byte:
 		switch opt.BytesOption {
 		case AsString:
-			return strconv.ParseUint(string(o), 10, 0)
+			return parseUint(string(o), opt)
 		case Binary:
-			i64, _ := binary.Uvarint(o)
-			return i64, nil
+			return decodeBinary(o, false, opt)
 		}
 	case string:
-		return strconv.ParseUint(string(o), 10, 0)
+		return parseUint(string(o), opt)
 	case bool:
 		if o {
 			return 1, nil
@@ -781,6 +994,14 @@ func toUint(i interface{}, opt SetOption) (uint64, error) {
 }
 
 func toString(i interface{}, opt SetOption) string {
+	if table, ok := opt.Enums[reflect.TypeOf(i)]; ok {
+		if name, ok := enumName(table, i); ok {
+			return name
+		}
+	}
+	if s, ok := netString(i); ok {
+		return s
+	}
 	switch o := i.(type) {
 	case string:
 		return o
@@ -815,11 +1036,7 @@ func toString(i interface{}, opt SetOption) string {
 		return strconv.FormatFloat(o, 'f', -1, 64)
 
 	case []byte:
-		switch opt.BytesOption {
-		case Base64:
-			return base64.StdEncoding.EncodeToString(o)
-		}
-		return string(o)
+		return encodeBytes(o, opt)
 	case fmt.Stringer:
 		return o.String()
 	}
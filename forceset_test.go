@@ -2,6 +2,7 @@ package forceset
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -307,3 +308,296 @@ func TestSetTime(t *testing.T) {
 		t.Fatal("expected nil got:", d.Next)
 	}
 }
+
+func TestSetSliceFromMapLimits(t *testing.T) {
+	var l []string
+	err := Set(&l, map[string]string{"1000000000": "x"})
+	if err == nil {
+		t.Fatal("expected length limit error got:", len(l))
+	}
+	err = Set(&l, map[string]string{"-1": "x"})
+	if err == nil {
+		t.Fatal("expected negative index error got:", l)
+	}
+	err = Set(&l, map[string]string{"a": "x"})
+	if err == nil {
+		t.Fatal("expected invalid index error got:", l)
+	}
+	err = Set(&l, map[int]string{0: "a", 9: "b"}, LimitSparseRatio(2))
+	if err == nil {
+		t.Fatal("expected sparse ratio error got:", l)
+	}
+	err = Set(&l, map[int]string{0: "a", 9: "b"}, SparseAs(SparseReject))
+	if err == nil {
+		t.Fatal("expected sparse error got:", l)
+	}
+	err = Set(&l, map[int]string{9: "b", 0: "a", 4: "c"}, SparseAs(SparseCompact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(l, []string{"a", "c", "b"}) {
+		t.Fatal("expected []string{\"a\", \"c\", \"b\"} got:", l)
+	}
+	type pair struct {
+		Key   int
+		Value string
+	}
+	var pairs []pair
+	err = Set(&pairs, map[int]string{0: "a", 1: "b", 2: "c"}, MapAsPairs, LimitSliceLength(2))
+	if err != nil || len(pairs) != 3 {
+		t.Fatal("expected pairs not limited got:", pairs, err)
+	}
+}
+
+func TestSetNeverPanics(t *testing.T) {
+	type Config struct {
+		Limits map[string]int
+	}
+	var c Config
+	err := ForceSet(reflect.ValueOf(c).FieldByName("Limits"), map[string]string{"a": "1"})
+	if err == nil {
+		t.Fatal("expected error for unaddressable destination")
+	}
+	if err := Set(nil, 1); err == nil {
+		t.Fatal("expected error for nil destination")
+	}
+	if err := Set(c, 1); err == nil {
+		t.Fatal("expected error for non-pointer destination")
+	}
+	var arr *[4]int
+	err = Set(&arr, []int{1})
+	var ce *ConversionError
+	if !errors.As(err, &ce) || !strings.Contains(ce.Reason, "forceset.") {
+		t.Fatal("expected conversion error with panic site got:", err)
+	}
+}
+
+func TestConversionErrorPath(t *testing.T) {
+	type Item struct {
+		Count int
+	}
+	type Order struct {
+		Items []Item
+	}
+	var o Order
+	err := Set(&o, map[string]interface{}{
+		"Items": []interface{}{
+			map[string]interface{}{"Count": 1},
+			map[string]interface{}{"Count": "x"},
+		},
+	})
+	var ce *ConversionError
+	if !errors.As(err, &ce) || ce.Path != "Items[1].Count" {
+		t.Fatal("expected error at Items[1].Count got:", err)
+	}
+}
+
+func FuzzSet(f *testing.F) {
+	f.Add([]byte(`{"Name":"Peter","Code":"2","TEXT":1}`))
+	f.Add([]byte(`{"1000000000":"x","-1":2}`))
+	f.Add([]byte(`[1,"2",null,{"a":[true]}]`))
+	f.Add([]byte(`"2020-05-19"`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var src interface{}
+		if json.Unmarshal(data, &src) != nil {
+			return
+		}
+		var (
+			i    int8
+			u    uint
+			s    string
+			b    []byte
+			l    []int
+			p    []struct{ Key, Value string }
+			m    map[int]*string
+			a    *[2]int
+			addr Address4
+			any  interface{}
+		)
+		for _, dst := range []interface{}{&i, &u, &s, &b, &l, &p, &m, &a, &addr, &any} {
+			_ = Set(dst, src)
+			_ = Set(dst, src, MapAsPairs)
+			_ = Set(dst, data)
+		}
+	})
+}
+
+func TestSetAllocatesNilMap(t *testing.T) {
+	type Limits struct {
+		Counts map[string]int
+	}
+	var l Limits
+	err := Set(&l, map[string]interface{}{"Counts": map[string]string{"a": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Counts["a"] != 1 {
+		t.Fatal("expected Counts[a] == 1 got:", l.Counts)
+	}
+	var m map[string]interface{}
+	err = Set(&m, Address2{Code: 1, Text: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["Text"] != "t" {
+		t.Fatal("expected Text == t got:", m)
+	}
+}
+
+func TestSetIntoInterfaceHoldingValue(t *testing.T) {
+	var dst interface{} = &Address2{}
+	err := Set(&dst, map[string]interface{}{"Code": "3", "Text": "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := dst.(*Address2); !ok || a.Code != 3 || a.Text != "t" {
+		t.Fatalf("%#v", dst)
+	}
+	var n interface{} = int64(0)
+	err = Set(&n, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(42) {
+		t.Fatalf("%#v", n)
+	}
+}
+
+func TestSetAtomic(t *testing.T) {
+	type Server struct {
+		Host   string
+		Limits map[string]int
+		Addr   *Address2
+		Port   int
+	}
+	s := Server{Host: "old", Limits: map[string]int{"a": 1}, Addr: &Address2{Code: 1}, Port: 80}
+	err := Set(&s, map[string]interface{}{
+		"Host":   "new",
+		"Limits": map[string]string{"a": "2"},
+		"Addr":   map[string]interface{}{"Code": 2},
+		"Port":   "bad",
+	}, Atomic)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if s.Host != "old" || s.Limits["a"] != 1 || s.Addr.Code != 1 || s.Port != 80 {
+		t.Fatalf("expected untouched destination got: %#v", s)
+	}
+	err = Set(&s, map[string]interface{}{"Host": "new", "Port": "81"}, Atomic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Host != "new" || s.Port != 81 || s.Limits["a"] != 1 {
+		t.Fatalf("%#v", s)
+	}
+}
+
+func TestSetStructFromSlice(t *testing.T) {
+	type Base struct {
+		ID int64
+	}
+	type Record struct {
+		*Base
+		Name  string
+		Email string `idx:"3"`
+		Age   int
+		note  string
+	}
+	var r Record
+	err := Set(&r, []string{"7", "Peter", "skipped", "peter@example.com", "30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Base == nil || r.ID != 7 || r.Name != "Peter" || r.Email != "peter@example.com" || r.Age != 30 {
+		t.Fatalf("%#v", r)
+	}
+	var row []interface{}
+	err = Set(&row, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []interface{}{int64(7), "Peter", nil, "peter@example.com", 30}
+	if !reflect.DeepEqual(row, expected) {
+		t.Fatalf("%#v", row)
+	}
+	err = Set(&r, [2]interface{}{1, "x"})
+	if err != nil || r.Name != "x" {
+		t.Fatal(r, err)
+	}
+	var tm time.Time
+	if err := Set(&tm, []int{1, 2}); err == nil {
+		t.Fatal("expected error")
+	}
+	if err := Set(&struct{}{}, []string{"a"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestSetIntParsing(t *testing.T) {
+	cases := map[string]int64{
+		"0x1F":   31,
+		"-0b101": -5,
+		"0o17":   15,
+		"1_000":  1000,
+		" 42 ":   42,
+		"1e3":    1000,
+		"3.0":    3,
+		"+5":     5,
+		"010":    10,
+	}
+	for s, expected := range cases {
+		var i int64
+		if err := Set(&i, s); err == nil && s != "+5" && s != "010" {
+			t.Fatal("expected strict error for", s, "got:", i)
+		}
+		err := Set(&i, s, ParseInts(IntLenient))
+		if err != nil || i != expected {
+			t.Fatal(s, i, err)
+		}
+	}
+	var u uint16
+	if err := Set(&u, "+0xff", ParseInts(IntAutoBase)); err != nil || u != 255 {
+		t.Fatal(u, err)
+	}
+	for _, s := range []string{"1__0", "_1", "3.5", "1e30", "0x"} {
+		var i int64
+		if err := Set(&i, s, ParseInts(IntLenient)); err == nil {
+			t.Fatal("expected error for", s, "got:", i)
+		}
+	}
+}
+
+func TestSetStructFromMapEmbeddedValue(t *testing.T) {
+	var s struct {
+		Address2
+		Name string
+	}
+	err := Set(&s, map[string]interface{}{"Code": "2", "Text": "t", "Name": "n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Code != 2 || s.Text != "t" || s.Name != "n" {
+		t.Fatalf("%#v", s)
+	}
+}
+
+func TestSetAtomicSharedAddress(t *testing.T) {
+	type Inner struct {
+		N int
+	}
+	type Outer struct {
+		In Inner
+	}
+	type A struct {
+		O *Outer
+		I *Inner
+	}
+	o := &Outer{}
+	a := A{O: o, I: &o.In}
+	if err := Set(&a, map[string]interface{}{"I": map[string]int{"N": 1}}, Atomic); err != nil {
+		t.Fatal(err)
+	}
+	if a.I.N != 1 {
+		t.Fatal(a.I)
+	}
+}
@@ -0,0 +1,146 @@
+package forceset
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// splitByTag converts a string or []byte i for a list or map destination using
+// the "sep:<sep>" tag option, and for maps the "kv:<sep>" option separating a
+// key from its value. ok is false when the tag has no such option.
+func splitByTag(value reflect.Value, i interface{}, tag string) (src interface{}, ok bool) {
+	var s string
+	switch o := i.(type) {
+	case string:
+		s = o
+	case []byte:
+		s = string(o)
+	default:
+		return nil, false
+	}
+	sep, hasSep := tagOption(tag, "sep")
+	kv, hasKV := tagOption(tag, "kv")
+	switch {
+	case value.Kind() == reflect.Map && hasKV:
+		if !hasSep {
+			sep = ","
+		}
+		m := map[string]string{}
+		for _, pair := range splitQuoted(s, sep) {
+			k, v := pair, ""
+			start := 0
+			if strings.HasPrefix(pair, `"`) {
+				start = closingQuote(pair) + 1
+			}
+			if n := strings.Index(pair[start:], kv); n >= 0 {
+				k, v = pair[:start+n], pair[start+n+len(kv):]
+			}
+			m[unquote(strings.TrimSpace(k))] = unquote(strings.TrimSpace(v))
+		}
+		return m, true
+	case isList(value.Type()) && hasSep:
+		items := splitQuoted(s, sep)
+		for n, item := range items {
+			items[n] = unquote(item)
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// joinByTag is the inverse of splitByTag for a string destination, it quotes
+// items that contain a separator, a quote or surrounding spaces.
+func joinByTag(i interface{}, opt SetOption, tag string) (s string, ok bool, err error) {
+	sep, hasSep := tagOption(tag, "sep")
+	kv, hasKV := tagOption(tag, "kv")
+	v := reflect.ValueOf(i)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+	var items []string
+	switch {
+	case v.Kind() == reflect.Map && hasKV:
+		if !hasSep {
+			sep = ","
+		}
+		keys := v.MapKeys()
+		sortKeys(keys)
+		for _, key := range keys {
+			var k, val string
+			if err := forceSet(reflect.ValueOf(&k), key.Interface(), opt, ""); err != nil {
+				return "", true, err
+			}
+			if err := forceSet(reflect.ValueOf(&val), v.MapIndex(key).Interface(), opt, ""); err != nil {
+				return "", true, withPath(err, "["+k+"]")
+			}
+			items = append(items, quoteItem(k, sep, kv)+kv+quoteItem(val, sep, kv))
+		}
+	case isList(v.Type()) && hasSep:
+		for n := 0; n < v.Len(); n++ {
+			var item string
+			if err := forceSet(reflect.ValueOf(&item), v.Index(n).Interface(), opt, ""); err != nil {
+				return "", true, withPath(err, "["+strconv.Itoa(n)+"]")
+			}
+			items = append(items, quoteItem(item, sep, ""))
+		}
+	default:
+		return "", false, nil
+	}
+	return strings.Join(items, sep), true, nil
+}
+
+// splitQuoted splits s on sep and trims the items. An item in double quotes
+// may contain sep, its quotes are kept for unquote.
+func splitQuoted(s, sep string) []string {
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+	var items []string
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		end := 0
+		if strings.HasPrefix(s, `"`) {
+			end = closingQuote(s) + 1
+		}
+		n := strings.Index(s[end:], sep)
+		if n < 0 || sep == "" {
+			return append(items, strings.TrimSpace(s))
+		}
+		items = append(items, strings.TrimSpace(s[:end+n]))
+		s = s[end+n+len(sep):]
+	}
+}
+
+// closingQuote returns the index of the quote closing the one s starts with.
+func closingQuote(s string) int {
+	for n := 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			n++
+		case '"':
+			return n
+		}
+	}
+	return len(s) - 1
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	return s
+}
+
+func quoteItem(s, sep, kv string) string {
+	if s != strings.TrimSpace(s) || strings.Contains(s, `"`) ||
+		(sep != "" && strings.Contains(s, sep)) || (kv != "" && strings.Contains(s, kv)) {
+		return strconv.Quote(s)
+	}
+	return s
+}
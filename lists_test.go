@@ -0,0 +1,47 @@
+package forceset
+
+import (
+	"reflect"
+	"testing"
+)
+
+type listConfig struct {
+	IDs    []int             `json:"ids;sep:,"`
+	Names  []string          `json:"names;sep:|"`
+	Labels map[string]string `json:"labels;kv:=;sep:,"`
+}
+
+func TestSetSplitsAndJoinsByTag(t *testing.T) {
+	src := map[string]string{
+		"ids":    " 1, 2 ,3",
+		"names":  `a | "b|c" | "d \"e\""`,
+		"labels": `env=prod, "team=x"=core,empty=`,
+	}
+	var c listConfig
+	err := Set(&c, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := listConfig{
+		IDs:    []int{1, 2, 3},
+		Names:  []string{"a", "b|c", `d "e"`},
+		Labels: map[string]string{"env": "prod", "team=x": "core", "empty": ""},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("%#v", c)
+	}
+
+	back := map[string]string{}
+	err = Set(&back, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back["ids"] != "1,2,3" || back["names"] != `a|"b|c"|"d \"e\""` || back["labels"] != `empty=,env=prod,"team=x"=core` {
+		t.Fatalf("%#v", back)
+	}
+	var again listConfig
+	err = Set(&again, back)
+	if err != nil || !reflect.DeepEqual(again, expected) {
+		t.Fatalf("%#v %v", again, err)
+	}
+}
@@ -0,0 +1,250 @@
+package forceset
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Loader fills a struct from layers of configuration. Layers are applied in
+// the order they are added with the merge semantics of Set, so a layer only
+// overrides the fields it supplies. Loader remembers which layer supplied the
+// final value of each field, see Provenance.
+type Loader struct {
+	dst        reflect.Value
+	initial    reflect.Value
+	opt        SetOption
+	layers     []layerFunc
+	provenance map[string]string
+	defaults   bool
+	err        error
+}
+
+type layerFunc func(root reflect.Value, record func(path, source string)) error
+
+// NewLoader returns a Loader filling the struct dst points to.
+func NewLoader(dst interface{}, opts ...Option) *Loader {
+	l := &Loader{opt: NewSetOption(opts...)}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		l.err = errors.New("load into type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to struct")
+		return l
+	}
+	l.dst = v.Elem()
+	l.initial = deepCopy(l.dst, map[copyKey]reflect.Value{})
+	return l
+}
+
+// Defaults adds a layer setting every field from its `default:"..."` tag.
+func (l *Loader) Defaults() *Loader {
+	l.defaults = true
+	l.layers = append(l.layers, func(root reflect.Value, record func(path, source string)) error {
+		b := envBinder{lookup: func(string) (string, bool) { return "", false }, defaults: true, record: record, opt: l.opt}
+		_, err := b.bind(root, "", "")
+		return err
+	})
+	return l
+}
+
+// Source adds a layer converting src, usually a decoded map, into the struct.
+// Its fields are reported by Provenance as name.
+func (l *Loader) Source(name string, src interface{}) *Loader {
+	l.layers = append(l.layers, func(root reflect.Value, record func(path, source string)) error {
+		return l.setSource(root, name, src, record)
+	})
+	return l
+}
+
+// File adds a layer reading the file at path on every Load and decoding it
+// with decode, e.g. json.Unmarshal. Its fields are reported as "file:<path>".
+func (l *Loader) File(path string, decode func([]byte, interface{}) error) *Loader {
+	l.layers = append(l.layers, func(root reflect.Value, record func(path, source string)) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var src interface{}
+		if err := decode(data, &src); err != nil {
+			return errors.New("decode file " + path + ": " + err.Error())
+		}
+		return l.setSource(root, "file:"+path, src, record)
+	})
+	return l
+}
+
+// Env adds a layer reading environment variables as FromEnv does, defaults
+// and required fields are left to Defaults and Load. Its fields are reported
+// as "env:<NAME>".
+func (l *Loader) Env(prefix string) *Loader {
+	l.layers = append(l.layers, func(root reflect.Value, record func(path, source string)) error {
+		b := envBinder{lookup: os.LookupEnv, record: record, opt: l.opt}
+		_, err := b.bind(root, strings.TrimSuffix(prefix, "_"), "")
+		return err
+	})
+	return l
+}
+
+// Flags registers a flag for every field on fs, as RegisterFlags does, and
+// adds a layer applying the flags given on the command line. fs must be
+// parsed before Load. Its fields are reported as "flag:<name>".
+func (l *Loader) Flags(fs *flag.FlagSet) *Loader {
+	if l.err != nil {
+		return l
+	}
+	flags := map[*fieldFlag]bool{}
+	func() {
+		defer recoverPanic(&l.err)
+		// the Defaults layer applies the default tags shown
+		registerFlags(fs, l.dst, nil, "", "", l.opt, flags, l.defaults)
+	}()
+	l.layers = append(l.layers, func(root reflect.Value, record func(path, source string)) error {
+		return applyFlags(fs, root, flags, l.opt, record)
+	})
+	return l
+}
+
+// Load applies all layers onto the struct as it was when NewLoader was
+// called, so a value removed from a layer is gone after the next Load. The
+// struct is only changed if every layer succeeded and every field tagged
+// `required:"true"` was supplied.
+func (l *Loader) Load() (err error) {
+	if l.err != nil {
+		return l.err
+	}
+	defer recoverPanic(&err)
+	shadow := reflect.New(l.dst.Type()).Elem()
+	shadow.Set(deepCopy(l.initial, map[copyKey]reflect.Value{}))
+	provenance := map[string]string{}
+	record := func(path, source string) {
+		for p := range provenance {
+			if strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[") {
+				delete(provenance, p)
+			}
+		}
+		provenance[path] = source
+	}
+	for _, layer := range l.layers {
+		if err := layer(shadow, record); err != nil {
+			return err
+		}
+	}
+	if err := checkRequired(shadow.Type(), "", provenance); err != nil {
+		return err
+	}
+	l.dst.Set(shadow)
+	l.provenance = provenance
+	return nil
+}
+
+// Provenance returns the layer that supplied the field at path, such as
+// "Server.Port", in the last successful Load: "default", "file:<path>",
+// "env:<NAME>", "flag:<name>" or the name given to Source. It is empty if no
+// layer supplied the field.
+func (l *Loader) Provenance(path string) string {
+	return lookupProvenance(l.provenance, path)
+}
+
+// lookupProvenance falls back to the closest parent of path that was set as a whole.
+func lookupProvenance(provenance map[string]string, path string) string {
+	for {
+		if source, ok := provenance[path]; ok {
+			return source
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return ""
+		}
+		path = path[:i]
+	}
+}
+
+func (l *Loader) setSource(root reflect.Value, name string, src interface{}, record func(path, source string)) error {
+	if err := forceSet(root, src, l.opt, ""); err != nil {
+		ce, ok := err.(*ConversionError)
+		if !ok {
+			ce = &ConversionError{Reason: err.Error(), Err: err}
+		}
+		ce.Reason = name + ": " + ce.Reason
+		return ce
+	}
+	sourcePaths(root.Type(), reflect.ValueOf(src), "", l.opt, func(path string) {
+		record(path, name)
+	})
+	return nil
+}
+
+// sourcePaths calls record with the path of every struct field that src, a
+// map as read by map2Struct, supplies.
+func sourcePaths(typ reflect.Type, src reflect.Value, path string, opt SetOption, record func(path string)) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	for src.Kind() == reflect.Ptr || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return
+		}
+		src = src.Elem()
+	}
+	if typ.Kind() != reflect.Struct || src.Kind() != reflect.Map || src.Type().Key().Kind() != reflect.String {
+		return
+	}
+	keyType := src.Type().Key()
+	for i := 0; i < typ.NumField(); i++ {
+		st := typ.Field(i)
+		if st.Anonymous {
+			sourcePaths(st.Type, src, path, opt, record)
+			continue
+		}
+		if st.PkgPath != "" {
+			continue
+		}
+		for _, name := range fieldNames(st, opt) {
+			value := src.MapIndex(reflect.ValueOf(name).Convert(keyType))
+			if value == empty {
+				continue
+			}
+			fieldPath := joinPath(path, st.Name)
+			if isNestedStruct(st.Type, opt) && isMap(value) {
+				// merged field by field
+				sourcePaths(st.Type, value, fieldPath, opt, record)
+			} else {
+				record(fieldPath)
+			}
+			break
+		}
+	}
+}
+
+func isMap(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Map
+}
+
+func checkRequired(typ reflect.Type, path string, provenance map[string]string) error {
+	for i := 0; i < typ.NumField(); i++ {
+		st := typ.Field(i)
+		if st.PkgPath != "" && !st.Anonymous {
+			continue
+		}
+		fieldPath := path
+		if !st.Anonymous {
+			fieldPath = joinPath(path, st.Name)
+		}
+		if st.Tag.Get("required") == "true" && lookupProvenance(provenance, fieldPath) == "" {
+			return &ConversionError{Path: fieldPath, Reason: "required field is not set by any layer"}
+		}
+		if st.Type.Kind() == reflect.Struct {
+			if err := checkRequired(st.Type, fieldPath, provenance); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package forceset
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type loaderConfig struct {
+	Name   string `json:"name" required:"true"`
+	Server struct {
+		Host string `json:"host" default:"localhost"`
+		Port int    `json:"port" default:"80"`
+	} `json:"server"`
+	Debug bool `json:"debug"`
+}
+
+func TestLoader(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.json")
+	local := filepath.Join(dir, "local.json")
+	_ = os.WriteFile(base, []byte(`{"name":"svc","server":{"host":"example.com","port":8080}}`), 0o600)
+	_ = os.WriteFile(local, []byte(`{"server":{"port":9090}}`), 0o600)
+	t.Setenv("APP_SERVER_PORT", "7070")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var c loaderConfig
+	l := NewLoader(&c).
+		Defaults().
+		File(base, json.Unmarshal).
+		File(local, json.Unmarshal).
+		Env("APP").
+		Flags(fs)
+	_ = fs.Parse([]string{"-debug"})
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "svc" || c.Server.Host != "example.com" || c.Server.Port != 7070 || !c.Debug {
+		t.Fatalf("%#v", c)
+	}
+	expected := map[string]string{
+		"Name":        "file:" + base,
+		"Server.Host": "file:" + base,
+		"Server.Port": "env:APP_SERVER_PORT",
+		"Debug":       "flag:debug",
+	}
+	for path, source := range expected {
+		if l.Provenance(path) != source {
+			t.Fatalf("expected %s from %s got: %s", path, source, l.Provenance(path))
+		}
+	}
+
+	_ = os.WriteFile(local, []byte(`{"server":{"port":"x"}}`), 0o600)
+	if err := l.Load(); err == nil {
+		t.Fatal("expected error")
+	}
+	if c.Server.Port != 7070 || l.Provenance("Server.Port") != "env:APP_SERVER_PORT" {
+		t.Fatalf("expected untouched config got: %#v", c)
+	}
+}
+
+func TestLoaderRequired(t *testing.T) {
+	var c loaderConfig
+	l := NewLoader(&c).Defaults().Source("inline", map[string]interface{}{"server": map[string]interface{}{"port": 1}})
+	if err := l.Load(); err == nil {
+		t.Fatal("expected required error")
+	}
+	if c.Server.Port != 0 {
+		t.Fatalf("expected untouched config got: %#v", c)
+	}
+	l.Source("name", map[string]interface{}{"name": "svc"})
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if l.Provenance("Server.Port") != "inline" || l.Provenance("Server.Host") != "default" {
+		t.Fatal(l.Provenance("Server.Port"), l.Provenance("Server.Host"))
+	}
+}
+
+func TestLoaderReloadDropsRemovedKeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	_ = os.WriteFile(file, []byte(`{"name":"svc","debug":true}`), 0o600)
+	var c loaderConfig
+	l := NewLoader(&c).File(file, json.Unmarshal)
+	if err := l.Load(); err != nil || !c.Debug {
+		t.Fatal(c, err)
+	}
+	_ = os.WriteFile(file, []byte(`{"name":"svc"}`), 0o600)
+	if err := l.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Debug || l.Provenance("Debug") != "" {
+		t.Fatal("expected Debug reset got:", c.Debug, l.Provenance("Debug"))
+	}
+}
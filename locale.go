@@ -0,0 +1,102 @@
+package forceset
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NumberLocale describes how numbers are written in strings, such as
+// "1,234.56" in English or "1.234,56" in German.
+type NumberLocale struct {
+	// Grouping separates thousands, it may be empty.
+	Grouping string
+	// Decimal separates the fraction.
+	Decimal string
+	// Currency lists symbols stripped before or after the number.
+	Currency []string
+}
+
+var currencySymbols = []string{"$", "€", "£", "¥", "CHF"}
+
+// numberLocales are the predefined locales, SetOption.NumberLocales may
+// override them.
+var numberLocales = map[string]NumberLocale{
+	"en": {Grouping: ",", Decimal: ".", Currency: currencySymbols},
+	"de": {Grouping: ".", Decimal: ",", Currency: currencySymbols},
+	"fr": {Grouping: " ", Decimal: ",", Currency: currencySymbols},
+	"ch": {Grouping: "'", Decimal: ".", Currency: currencySymbols},
+}
+
+// localizeNumber rewrites a string i for a numeric destination into the plain
+// form toInt and toFloat parse, using the locale named by the "locale:<name>"
+// tag option or else SetOption.NumberLocale. It returns i unchanged when no
+// locale applies.
+func localizeNumber(value reflect.Value, i interface{}, opt SetOption, tag string) (interface{}, error) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+	default:
+		return i, nil
+	}
+	loc := opt.NumberLocale
+	if name, ok := tagOption(tag, "locale"); ok {
+		l, found := opt.NumberLocales[name]
+		if !found {
+			l, found = numberLocales[name]
+		}
+		if !found {
+			return nil, errors.New("unknown number locale " + name)
+		}
+		loc = &l
+	}
+	if loc == nil {
+		return i, nil
+	}
+	switch o := i.(type) {
+	case string:
+		return normalizeNumber(o, *loc)
+	case []byte:
+		return normalizeNumber(string(o), *loc)
+	}
+	return i, nil
+}
+
+func normalizeNumber(s string, loc NumberLocale) (string, error) {
+	n := strings.TrimSpace(s)
+	if loc.Grouping == " " {
+		// no-break spaces are usual between groups
+		n = strings.NewReplacer("\u00a0", " ", "\u202f", " ").Replace(n)
+	}
+	for _, symbol := range loc.Currency {
+		n = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(n, symbol), symbol))
+	}
+	sign := ""
+	if strings.HasPrefix(n, "-") || strings.HasPrefix(n, "+") {
+		sign, n = n[:1], n[1:]
+		for _, symbol := range loc.Currency {
+			n = strings.TrimSpace(strings.TrimPrefix(n, symbol))
+		}
+	}
+	integer, fraction := n, ""
+	hasFraction := false
+	if loc.Decimal != "" {
+		if i := strings.LastIndex(n, loc.Decimal); i >= 0 {
+			integer, fraction, hasFraction = n[:i], n[i+len(loc.Decimal):], true
+		}
+	}
+	if loc.Grouping != "" && strings.Contains(integer, loc.Grouping) {
+		groups := strings.Split(integer, loc.Grouping)
+		for i, group := range groups {
+			if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+				return "", errors.New("invalid digit grouping in number " + strconv.Quote(s))
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	if hasFraction {
+		return sign + integer + "." + fraction, nil
+	}
+	return sign + integer, nil
+}
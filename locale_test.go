@@ -0,0 +1,48 @@
+package forceset
+
+import "testing"
+
+func TestSetNumberLocale(t *testing.T) {
+	type Sheet struct {
+		US    float64 `json:"us;locale:en"`
+		DE    float64 `json:"de;locale:de"`
+		FR    int     `json:"fr;locale:fr"`
+		Price float64 `json:"price;locale:de"`
+		Plain float64 `json:"plain"`
+	}
+	var s Sheet
+	err := Set(&s, map[string]interface{}{
+		"us":    "1,234.56",
+		"de":    []byte("1.234,56"),
+		"fr":    "1 234",
+		"price": "-€ 2.500,5",
+		"plain": "1234.5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.US != 1234.56 || s.DE != 1234.56 || s.FR != 1234 || s.Price != -2500.5 || s.Plain != 1234.5 {
+		t.Fatalf("%#v", s)
+	}
+	if err := Set(&s, map[string]interface{}{"us": "1,5"}); err == nil {
+		t.Fatal("expected grouping error got:", s.US)
+	}
+	var f float64
+	if err := Set(&f, "$12,000.25", UseNumberLocale(NumberLocale{Grouping: ",", Decimal: ".", Currency: []string{"$"}})); err != nil || f != 12000.25 {
+		t.Fatal(f, err)
+	}
+	in := map[string]interface{}{"us": "1.234,5", "de": "1.234,5"}
+	if err := Set(&s, in, RegisterNumberLocale("en", numberLocales["de"])); err != nil || s.US != 1234.5 {
+		t.Fatal(s.US, err)
+	}
+	if err := Set(&s, in); err == nil || numberLocales["en"].Decimal != "." {
+		t.Fatal("expected predefined en locale got:", s.US)
+	}
+	type Custom struct {
+		N int `json:"n;locale:in"`
+	}
+	var c Custom
+	if err := Set(&c, map[string]string{"n": "1_000"}, RegisterNumberLocale("in", NumberLocale{Grouping: "_"})); err != nil || c.N != 1000 {
+		t.Fatal(c, err)
+	}
+}
@@ -0,0 +1,108 @@
+package forceset
+
+import (
+	"errors"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+var (
+	ipType           = reflect.TypeOf(net.IP{})
+	ipNetType        = reflect.TypeOf(net.IPNet{})
+	hardwareAddrType = reflect.TypeOf(net.HardwareAddr{})
+	addrType         = reflect.TypeOf(netip.Addr{})
+	prefixType       = reflect.TypeOf(netip.Prefix{})
+	urlType          = reflect.TypeOf(url.URL{})
+	mailAddressType  = reflect.TypeOf(mail.Address{})
+	regexpType       = reflect.TypeOf(regexp.Regexp{})
+)
+
+// setNetType parses strings and []byte into the network types, *net.IPNet
+// from CIDR notation, and writes url.URL, mail.Address and regexp.Regexp
+// values into strings. A []byte of 4 or 16 bytes that is not text is left to
+// the usual conversion into a net.IP.
+func setNetType(value reflect.Value, i interface{}) (bool, error) {
+	if value.Kind() == reflect.String {
+		s, ok := netString(i)
+		if ok {
+			value.SetString(s)
+		}
+		return ok, nil
+	}
+	var s string
+	switch o := i.(type) {
+	case string:
+		s = o
+	case []byte:
+		s = string(o)
+	default:
+		return false, nil
+	}
+	var parsed interface{}
+	var err error
+	switch value.Type() {
+	case ipType:
+		ip := net.ParseIP(s)
+		if ip == nil {
+			if b, ok := i.([]byte); ok && (len(b) == net.IPv4len || len(b) == net.IPv6len) {
+				return false, nil
+			}
+			err = errors.New("invalid IP address (" + s + ")")
+		}
+		parsed = ip
+	case ipNetType:
+		var ipNet *net.IPNet
+		if _, ipNet, err = net.ParseCIDR(s); err == nil {
+			parsed = *ipNet
+		}
+	case hardwareAddrType:
+		parsed, err = net.ParseMAC(s)
+	case addrType:
+		parsed, err = netip.ParseAddr(s)
+	case prefixType:
+		parsed, err = netip.ParsePrefix(s)
+	case urlType:
+		var u *url.URL
+		if u, err = url.Parse(s); err == nil {
+			parsed = *u
+		}
+	case mailAddressType:
+		var a *mail.Address
+		if a, err = mail.ParseAddress(s); err == nil {
+			parsed = *a
+		}
+	case regexpType:
+		var re *regexp.Regexp
+		if re, err = regexp.Compile(s); err == nil {
+			value.Set(reflect.ValueOf(re).Elem())
+			return true, nil
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	value.Set(reflect.ValueOf(parsed))
+	return true, nil
+}
+
+// netString formats the network types whose String method has a pointer
+// receiver, the others are fmt.Stringers.
+func netString(i interface{}) (string, bool) {
+	switch o := i.(type) {
+	case url.URL:
+		return o.String(), true
+	case net.IPNet:
+		return o.String(), true
+	case mail.Address:
+		return o.String(), true
+	case regexp.Regexp:
+		return o.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package forceset
+
+import (
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+type serviceConfig struct {
+	Listen  net.IP           `json:"listen"`
+	Allow   *net.IPNet       `json:"allow"`
+	Deny    net.IPNet        `json:"deny"`
+	MAC     net.HardwareAddr `json:"mac"`
+	Addr    netip.Addr       `json:"addr"`
+	Subnet  netip.Prefix     `json:"subnet"`
+	Backend url.URL          `json:"backend"`
+	Proxy   *url.URL         `json:"proxy"`
+	Admin   mail.Address     `json:"admin"`
+	Route   *regexp.Regexp   `json:"route"`
+	Match   regexp.Regexp    `json:"match"`
+}
+
+func TestSetNetTypes(t *testing.T) {
+	in := map[string]string{
+		"listen":  "10.0.0.1",
+		"allow":   "192.168.0.0/16",
+		"deny":    "10.2.0.0/16",
+		"mac":     "00:1a:2b:3c:4d:5e",
+		"addr":    "::1",
+		"subnet":  "10.1.0.0/24",
+		"backend": "http://backend:8080/api",
+		"proxy":   "socks5://proxy:1080",
+		"admin":   "Ops <ops@example.com>",
+		"route":   "^/v[0-9]+/",
+		"match":   "a+b",
+	}
+	var c serviceConfig
+	if err := Set(&c, in); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Listen.Equal(net.IPv4(10, 0, 0, 1)) || !c.Allow.Contains(net.IPv4(192, 168, 3, 4)) {
+		t.Fatal(c.Listen, c.Allow)
+	}
+	if c.Backend.Host != "backend:8080" || c.Proxy.Scheme != "socks5" || c.Admin.Address != "ops@example.com" {
+		t.Fatal(c.Backend, c.Proxy, c.Admin)
+	}
+	if !c.Route.MatchString("/v2/users") || !c.Match.MatchString("aab") {
+		t.Fatal(c.Route, &c.Match)
+	}
+
+	var out map[string]string
+	if err := Set(&out, c); err != nil {
+		t.Fatal(err)
+	}
+	in["admin"] = `"Ops" <ops@example.com>`
+	for k, v := range in {
+		if out[k] != v {
+			t.Fatal(k, out[k], "!=", v)
+		}
+	}
+
+	var ip net.IP
+	if err := Set(&ip, []byte{127, 0, 0, 1}); err != nil || ip.String() != "127.0.0.1" {
+		t.Fatal(ip, err)
+	}
+	for field, bad := range map[string]string{"listen": "10.0.0", "allow": "10.0.0.0", "subnet": "x", "route": "("} {
+		if err := Set(&c, map[string]string{field: bad}); err == nil {
+			t.Fatal("expected error for", field, bad)
+		}
+	}
+}
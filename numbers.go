@@ -0,0 +1,115 @@
+package forceset
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// parseInt parses a decimal integer, or with SetOption.IntParsing the
+// lenient forms it enables.
+func parseInt(s string, opt SetOption) (int64, error) {
+	if opt.IntParsing == 0 {
+		return strconv.ParseInt(s, 10, 0)
+	}
+	neg, digits, base, err := splitInt(s, opt)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		digits = "-" + digits
+	}
+	i, err := strconv.ParseInt(digits, base, 0)
+	if err != nil && opt.IntParsing&IntFromFloat != 0 {
+		n, ferr := integralFloat(s, opt)
+		if ferr != nil {
+			return 0, err
+		}
+		if !n.IsInt64() {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+		}
+		return n.Int64(), nil
+	}
+	return i, err
+}
+
+func parseUint(s string, opt SetOption) (uint64, error) {
+	if opt.IntParsing == 0 {
+		return strconv.ParseUint(s, 10, 0)
+	}
+	neg, digits, base, err := splitInt(s, opt)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		digits = "-" + digits
+	}
+	u, err := strconv.ParseUint(digits, base, 0)
+	if err != nil && opt.IntParsing&IntFromFloat != 0 {
+		n, ferr := integralFloat(s, opt)
+		if ferr != nil {
+			return 0, err
+		}
+		if n.Sign() < 0 || !n.IsUint64() {
+			return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+		}
+		return n.Uint64(), nil
+	}
+	return u, err
+}
+
+// splitInt trims, takes the sign and the base prefix off and removes the
+// underscores of s as enabled by SetOption.IntParsing.
+func splitInt(s string, opt SetOption) (neg bool, digits string, base int, err error) {
+	if opt.IntParsing&IntTrimSpace != 0 {
+		s = strings.TrimSpace(s)
+	}
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	base = 10
+	if opt.IntParsing&IntAutoBase != 0 && len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 10 {
+			s = strings.TrimPrefix(s[2:], "_")
+		}
+	}
+	if opt.IntParsing&IntUnderscores != 0 && strings.Contains(s, "_") {
+		if strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+			return false, "", 0, errors.New("invalid underscores in integer " + strconv.Quote(s))
+		}
+		s = strings.ReplaceAll(s, "_", "")
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		return false, "", 0, errors.New("invalid sign in integer " + strconv.Quote(s))
+	}
+	return neg, s, base, nil
+}
+
+// integralFloat parses a decimal float string like "1e3" or "3.0" exactly and
+// fails unless it is an integer.
+func integralFloat(s string, opt SetOption) (*big.Int, error) {
+	if opt.IntParsing&IntTrimSpace != 0 {
+		s = strings.TrimSpace(s)
+	}
+	if opt.IntParsing&IntUnderscores != 0 {
+		s = strings.ReplaceAll(s, "_", "")
+	}
+	if strings.ContainsAny(s, "/xXpP") {
+		return nil, errors.New("not a decimal float")
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || !r.IsInt() {
+		return nil, errors.New("not an integral float")
+	}
+	return r.Num(), nil
+}
@@ -8,6 +8,12 @@ const (
 	AsString BytesOption = iota
 	Base64
 	Binary
+	Hex
+	// Base64URL is padded base64 with the URL safe alphabet.
+	Base64URL
+	// RawBase64 is base64 with the standard alphabet and no padding.
+	RawBase64
+	Base32
 )
 
 type MapToSliceOption uint8
@@ -17,12 +23,97 @@ const (
 	Pairs
 )
 
+// IntParsing enables lenient forms of integer strings, by default only
+// decimal digits with an optional sign are accepted.
+type IntParsing uint8
+
+const (
+	// IntAutoBase accepts the 0x, 0o and 0b prefixes.
+	IntAutoBase IntParsing = 1 << iota
+	// IntUnderscores accepts underscores between digits as in "1_000".
+	IntUnderscores
+	// IntTrimSpace ignores surrounding white space.
+	IntTrimSpace
+	// IntFromFloat accepts integral float strings such as "1e3" and "3.0".
+	IntFromFloat
+
+	IntLenient = IntAutoBase | IntUnderscores | IntTrimSpace | IntFromFloat
+)
+
+// BinaryEncoding is how numbers and bools are written to and read from
+// []byte with the Binary BytesOption.
+type BinaryEncoding uint8
+
+const (
+	// FixedLittleEndian writes the fixed width of the type, int and uint as 8 bytes.
+	FixedLittleEndian BinaryEncoding = iota
+	FixedBigEndian
+	// Varint writes signed integers zigzag encoded and the rest as unsigned varints.
+	Varint
+)
+
+// SparsePolicy decides how an ArrayLike map whose keys leave gaps becomes a slice.
+type SparsePolicy uint8
+
+const (
+	// SparseFill places every value at its key and leaves the gaps as zero values.
+	SparseFill SparsePolicy = iota
+	// SparseCompact drops the gaps and keeps the values in key order.
+	SparseCompact
+	// SparseReject fails unless the keys are exactly 0..n-1.
+	SparseReject
+)
+
+// DefaultMaxSliceLength is the MaxSliceLength used when no option overrides it.
+const DefaultMaxSliceLength = 1 << 16
+
 type SetOption struct {
 	BytesOption      BytesOption
 	Tag              string
 	MapToSliceOption MapToSliceOption
 	Mappers          map[MapperType]Mapper
-	Decoder          func([]byte, interface{}) error
+	// Decoder decodes JSON and other string and []byte sources whose format
+	// is neither selected by a decode tag option nor sniffed as kv or a byte
+	// encoding. nil turns decoding off for fields without a decode tag option.
+	Decoder func([]byte, interface{}) error
+	// Decoders maps a format name to its decoder, see RegisterDecoder.
+	Decoders map[string]func([]byte, interface{}) error
+	// Encoder encodes struct, map and list sources set into a string or
+	// []byte unless an encode tag option selects another one.
+	Encoder func(interface{}) ([]byte, error)
+	// Encoders maps a format name to its encoder, see RegisterEncoder.
+	Encoders map[string]func(interface{}) ([]byte, error)
+	// MaxSliceLength limits the length of an ArrayLike slice built from the
+	// indexes of a map, 0 means unlimited.
+	MaxSliceLength int
+	// MaxSparseRatio limits slice length divided by map entries, 0 means unlimited.
+	MaxSparseRatio float64
+	SparsePolicy   SparsePolicy
+	// Atomic leaves the destination untouched when the conversion fails.
+	Atomic bool
+	// EnvSeparator splits environment variables bound to slice fields.
+	EnvSeparator string
+	IntParsing   IntParsing
+	// NumberLocale, if not nil, is how numeric strings are written unless
+	// a locale tag option names one of NumberLocales or the predefined ones.
+	NumberLocale  *NumberLocale
+	NumberLocales map[string]NumberLocale
+	// BinaryEncoding applies when BytesOption is Binary.
+	BinaryEncoding BinaryEncoding
+	// DetectBytes decodes strings set into []byte as hex, base32 or base64
+	// when they are valid in one of them, before applying BytesOption.
+	DetectBytes bool
+	// Enums maps an enum type to its names, see RegisterEnum.
+	Enums       map[reflect.Type]map[string]interface{}
+	StrictEnums bool
+	// Types maps an interface type to its concrete types by name, see RegisterType.
+	Types map[reflect.Type]map[string]reflect.Type
+	// Discriminator is the map key naming the concrete type of an interface,
+	// the tag option "type:<key>" overrides it.
+	Discriminator string
+	// discriminator is the type:<key> override of the field being converted,
+	// it applies to the field and its list or map elements only.
+	discriminator string
 }
 
 type Mapper func(dst reflect.Value, src reflect.Value, tag string) error
@@ -41,3 +132,79 @@ func MapAsPairs(opt *SetOption) {
 func MapAsArrayLike(opt *SetOption) {
 	opt.MapToSliceOption = ArrayLike
 }
+
+func LimitSliceLength(n int) Option {
+	return func(opt *SetOption) {
+		opt.MaxSliceLength = n
+	}
+}
+
+func LimitSparseRatio(ratio float64) Option {
+	return func(opt *SetOption) {
+		opt.MaxSparseRatio = ratio
+	}
+}
+
+func ParseInts(flags IntParsing) Option {
+	return func(opt *SetOption) {
+		opt.IntParsing = flags
+	}
+}
+
+func EnvSeparator(sep string) Option {
+	return func(opt *SetOption) {
+		opt.EnvSeparator = sep
+	}
+}
+
+func SparseAs(policy SparsePolicy) Option {
+	return func(opt *SetOption) {
+		opt.SparsePolicy = policy
+	}
+}
+
+// RegisterDecoder adds a decoder selectable per field by the tag option
+// "decode:<name>", e.g. `json:"spec;decode:yaml"`.
+func RegisterDecoder(name string, decode func([]byte, interface{}) error) Option {
+	return func(opt *SetOption) {
+		opt.Decoders[name] = decode
+	}
+}
+
+// RegisterEncoder adds an encoder selectable per field by the tag option
+// "encode:<name>", e.g. `db:"spec;encode:yaml"`.
+func RegisterEncoder(name string, encode func(interface{}) ([]byte, error)) Option {
+	return func(opt *SetOption) {
+		opt.Encoders[name] = encode
+	}
+}
+
+// DetectBytes enables lenient detection of the encoding of byte strings.
+func DetectBytes(opt *SetOption) {
+	opt.DetectBytes = true
+}
+
+// BinaryAs converts numbers and bools to and from []byte with enc.
+func BinaryAs(enc BinaryEncoding) Option {
+	return func(opt *SetOption) {
+		opt.BytesOption = Binary
+		opt.BinaryEncoding = enc
+	}
+}
+
+func UseNumberLocale(loc NumberLocale) Option {
+	return func(opt *SetOption) {
+		opt.NumberLocale = &loc
+	}
+}
+
+// RegisterNumberLocale adds a locale selectable per field by the tag option
+// "locale:<name>", "en", "de", "fr" and "ch" are predefined.
+func RegisterNumberLocale(name string, loc NumberLocale) Option {
+	return func(opt *SetOption) {
+		if opt.NumberLocales == nil {
+			opt.NumberLocales = map[string]NumberLocale{}
+		}
+		opt.NumberLocales[name] = loc
+	}
+}
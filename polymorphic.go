@@ -0,0 +1,102 @@
+package forceset
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// RegisterType registers the concrete type of v under name for the interface
+// I, e.g. RegisterType[Shape]("circle", &Circle{}). A map set into an I reads
+// the name from its discriminator key and fills a new value of that type.
+func RegisterType[I any](name string, v I) Option {
+	return func(opt *SetOption) {
+		it := reflect.TypeOf((*I)(nil)).Elem()
+		if opt.Types[it] == nil {
+			opt.Types[it] = map[string]reflect.Type{}
+		}
+		opt.Types[it][name] = reflect.TypeOf(v)
+	}
+}
+
+// setPolymorphic fills a registered interface from a map holding the
+// discriminator, other sources are left to the usual conversion.
+func setPolymorphic(value reflect.Value, i interface{}, opt SetOption) (bool, error) {
+	types, ok := opt.Types[value.Type()]
+	if !ok || value.Kind() != reflect.Interface {
+		return false, nil
+	}
+	iv := reflect.ValueOf(i)
+	if iv.Kind() != reflect.Map || iv.Type().Key().Kind() != reflect.String {
+		return false, nil
+	}
+	key := opt.Discriminator
+	if opt.discriminator != "" {
+		key = opt.discriminator
+	}
+	kind := iv.MapIndex(reflect.ValueOf(key).Convert(iv.Type().Key()))
+	if !kind.IsValid() {
+		return false, nil
+	}
+	name := toString(kind.Interface(), opt)
+	t, ok := types[name]
+	if !ok {
+		return true, errors.New("unknown " + key + " (" + name + ") for type(" + value.Type().String() + ")")
+	}
+	concrete := reflect.New(t).Elem()
+	if !value.IsNil() && value.Elem().Type() == t {
+		concrete.Set(value.Elem())
+	}
+	opt.discriminator = ""
+	if err := forceSet(concrete, i, opt, ""); err != nil {
+		return true, err
+	}
+	value.Set(concrete)
+	return true, nil
+}
+
+// withDiscriminator returns the source struct2map sets for the field v:
+// a registered interface becomes a map holding its discriminator, so does
+// each element of a list of them.
+func withDiscriminator(v reflect.Value, key string, opt SetOption) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		types, ok := opt.Types[v.Type()]
+		if !ok || v.IsNil() {
+			break
+		}
+		name := ""
+		for n, t := range types {
+			if t == v.Elem().Type() && (name == "" || n < name) {
+				name = n
+			}
+		}
+		if name == "" {
+			break
+		}
+		concrete := v.Elem()
+		for concrete.Kind() == reflect.Ptr && !concrete.IsNil() {
+			concrete = concrete.Elem()
+		}
+		m := map[string]interface{}{}
+		if err := forceSet(reflect.ValueOf(&m).Elem(), concrete.Interface(), opt, ""); err != nil {
+			return nil, err
+		}
+		m[key] = name
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if _, ok := opt.Types[v.Type().Elem()]; !ok || v.Kind() == reflect.Slice && v.IsNil() {
+			break
+		}
+		list := make([]interface{}, v.Len())
+		for n := range list {
+			item, err := withDiscriminator(v.Index(n), key, opt)
+			if err != nil {
+				return nil, withPath(err, "["+strconv.Itoa(n)+"]")
+			}
+			list[n] = item
+		}
+		return list, nil
+	}
+	return v.Interface(), nil
+}
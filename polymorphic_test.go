@@ -0,0 +1,98 @@
+package forceset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type shape interface {
+	Area() float64
+}
+
+type circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+func (s square) Area() float64 { return s.Side * s.Side }
+
+type drawing struct {
+	Main   shape   `json:"main;type:kind"`
+	Others []shape `json:"others;type:kind"`
+}
+
+func TestSetPolymorphic(t *testing.T) {
+	opts := []Option{RegisterType[shape]("circle", &circle{}), RegisterType[shape]("square", square{})}
+	var src map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"main": {"kind": "circle", "radius": 2},
+		"others": [{"kind": "square", "side": 3}, {"kind": "circle", "radius": 1}]
+	}`), &src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d drawing
+	if err := Set(&d, src, opts...); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := d.Main.(*circle); !ok || c.Radius != 2 {
+		t.Fatal(d.Main)
+	}
+	if len(d.Others) != 2 || d.Others[0].Area() != 9 || d.Others[1].Area() != 3 {
+		t.Fatal(d.Others)
+	}
+
+	var out map[string]interface{}
+	if err := Set(&out, d, opts...); err != nil {
+		t.Fatal(err)
+	}
+	main := out["main"].(map[string]interface{})
+	if main["kind"] != "circle" || main["radius"] != 2.0 {
+		t.Fatal(main)
+	}
+	others := out["others"].([]interface{})
+	if others[0].(map[string]interface{})["kind"] != "square" {
+		t.Fatal(others)
+	}
+
+	err = Set(&d, map[string]interface{}{"main": map[string]interface{}{"kind": "hexagon"}}, opts...)
+	if err == nil {
+		t.Fatal("expected error for unknown kind got:", d.Main)
+	}
+
+	opts = append(opts, RegisterType[shape]("round", &circle{}), RegisterType[shape]("disc", &circle{}))
+	for n := 0; n < 20; n++ {
+		if err := Set(&out, d, opts...); err != nil || out["main"].(map[string]interface{})["kind"] != "circle" {
+			t.Fatal(out["main"], err)
+		}
+	}
+}
+
+type group struct {
+	Children []shape `json:"children"`
+}
+
+func (g *group) Area() float64 { return 0 }
+
+func TestSetPolymorphicNestedKey(t *testing.T) {
+	opts := []Option{RegisterType[shape]("group", &group{}), RegisterType[shape]("square", square{})}
+	var d drawing
+	err := Set(&d, map[string]interface{}{
+		"main": map[string]interface{}{
+			"kind":     "group",
+			"children": []interface{}{map[string]interface{}{"type": "square", "side": 2}},
+		},
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := d.Main.(*group)
+	if !ok || len(g.Children) != 1 || g.Children[0].Area() != 4 {
+		t.Fatal(d.Main)
+	}
+}
@@ -0,0 +1,90 @@
+package forceset
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// positionalField is a struct field converted by its position in a slice.
+type positionalField struct {
+	index []int
+	name  string
+	pos   int
+}
+
+// positionalFields lists the exported fields of typ in order, embedded struct
+// fields in place of their struct. A field is at the position of its
+// `idx:"n"` tag or else right after the previous field.
+func positionalFields(typ reflect.Type) []positionalField {
+	var fields []positionalField
+	next := 0
+	var walk func(typ reflect.Type, index []int)
+	walk = func(typ reflect.Type, index []int) {
+		for i := 0; i < typ.NumField(); i++ {
+			st := typ.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+			if st.Anonymous {
+				ft := st.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft, fieldIndex)
+					continue
+				}
+			}
+			if st.PkgPath != "" {
+				continue
+			}
+			pos := next
+			if idx, err := strconv.Atoi(st.Tag.Get("idx")); err == nil && idx >= 0 {
+				pos = idx
+			}
+			fields = append(fields, positionalField{index: fieldIndex, name: st.Name, pos: pos})
+			next = pos + 1
+		}
+	}
+	walk(typ, nil)
+	return fields
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that either allocates nil
+// embedded pointers or reports false when it meets one.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for n, i := range index {
+		if n > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					if !alloc {
+						return v, false
+					}
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// dst struct
+// src slice or array
+func slice2struct(dst, src reflect.Value, opt SetOption) error {
+	fields := positionalFields(dst.Type())
+	if len(fields) == 0 {
+		return errors.New("force set type(" + src.Type().String() + ") into type(" + dst.Type().String() + ") failed")
+	}
+	for _, f := range fields {
+		if f.pos >= src.Len() {
+			continue
+		}
+		field, _ := fieldByIndex(dst, f.index, true)
+		err := forceSet(field, src.Index(f.pos).Interface(), opt, "")
+		if err != nil {
+			return withPath(err, f.name)
+		}
+	}
+	return nil
+}
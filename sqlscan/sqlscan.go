@@ -0,0 +1,164 @@
+// Package sqlscan fills structs from database/sql rows using forceset conversions.
+//
+// Columns are matched to fields by the `db` tag, or else by the field name
+// compared case insensitively or in snake case, so "user_name" fills UserName.
+// Values go through forceset's loose conversions, so numbers returned as
+// []byte by MySQL style drivers land in int fields and NULL leaves the zero value.
+package sqlscan
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/cocotyty/forceset"
+)
+
+// ScanRow scans the current row of rows into dst, a pointer to a struct or,
+// for a single column, to any value forceset can set.
+func ScanRow(rows *sql.Rows, dst interface{}, opts ...forceset.Option) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("scan into type(" + reflect.TypeOf(dst).String() + ") failed: must be a non-nil pointer")
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	opts = withTag(opts)
+	return scan(rows, columns, columnKeys(v.Elem().Type(), opts), v.Elem(), opts)
+}
+
+// ScanRows scans every row of rows into dst, a pointer to a slice, and closes rows.
+func ScanRows(rows *sql.Rows, dst interface{}, opts ...forceset.Option) error {
+	defer rows.Close()
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.New("scan into type(" + reflect.TypeOf(dst).String() + ") failed: must be a pointer to slice")
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	opts = withTag(opts)
+	slice := v.Elem()
+	keys := columnKeys(slice.Type().Elem(), opts)
+	list := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		item := reflect.New(slice.Type().Elem()).Elem()
+		if err := scan(rows, columns, keys, item, opts); err != nil {
+			return err
+		}
+		list = reflect.Append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	slice.Set(list)
+	return nil
+}
+
+func withTag(opts []forceset.Option) []forceset.Option {
+	return append([]forceset.Option{func(opt *forceset.SetOption) {
+		opt.Tag = "db"
+	}}, opts...)
+}
+
+// scan converts the current row into dst by keys, the result of columnKeys
+// for the type of dst.
+func scan(rows *sql.Rows, columns []string, keys map[string]string, dst reflect.Value, opts []forceset.Option) error {
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return err
+	}
+	if keys == nil || len(columns) == 1 && columnKey(keys, columns[0]) == "" {
+		if len(columns) != 1 {
+			return errors.New("scan " + dst.Type().String() + " from " + strings.Join(columns, ",") + " failed: need a struct for more than one column")
+		}
+		return forceset.ForceSet(dst, values[0], opts...)
+	}
+	m := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if key := columnKey(keys, column); key != "" {
+			m[key] = values[i]
+		}
+	}
+	return forceset.ForceSet(dst, m, opts...)
+}
+
+func columnKey(keys map[string]string, column string) string {
+	if key, ok := keys[column]; ok {
+		return key
+	}
+	return keys[strings.ToLower(column)]
+}
+
+// columnKeys maps column names to the map key forceset looks the field up by,
+// it is nil unless typ is a struct or a pointer to one.
+func columnKeys(typ reflect.Type, opts []forceset.Option) map[string]string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	opt := forceset.NewSetOption(opts...)
+	keys := map[string]string{}
+	var walk func(typ reflect.Type)
+	walk = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			st := typ.Field(i)
+			if st.Anonymous {
+				ft := st.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					walk(ft)
+					continue
+				}
+			}
+			if st.PkgPath != "" {
+				continue
+			}
+			tag := strings.Split(strings.Split(st.Tag.Get(opt.Tag), ";")[0], " ")
+			if tag[0] == "-" {
+				continue
+			}
+			if tag[0] != "" {
+				for _, name := range tag {
+					keys[name] = name
+				}
+				continue
+			}
+			keys[st.Name] = st.Name
+			keys[strings.ToLower(st.Name)] = st.Name
+			keys[toSnake(st.Name)] = st.Name
+		}
+	}
+	walk(typ)
+	return keys
+}
+
+// toSnake turns "UserID" into "user_id".
+func toSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package sqlscan
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cocotyty/forceset"
+)
+
+// fakeDriver answers every query with the result registered under its text.
+type fakeDriver struct{}
+
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+var results = map[string]fakeResult{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	result, ok := results[query]
+	if !ok {
+		return nil, errors.New("unknown query " + query)
+	}
+	return fakeStmt{result}, nil
+}
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct{ result fakeResult }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{result: s.result}, nil
+}
+
+type fakeRows struct {
+	result fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string { return r.result.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func init() {
+	sql.Register("forceset-fake", fakeDriver{})
+}
+
+type User struct {
+	ID        int64
+	UserName  string
+	Score     float64 `db:"points"`
+	Active    bool
+	CreatedAt time.Time
+	Nickname  *string
+}
+
+func TestScanRows(t *testing.T) {
+	created := time.Date(2020, 5, 19, 16, 20, 17, 0, time.UTC)
+	results["users"] = fakeResult{
+		columns: []string{"id", "user_name", "points", "ACTIVE", "created_at", "nickname", "unknown"},
+		rows: [][]driver.Value{
+			{[]byte("1"), []byte("peter"), []byte("9.5"), int64(1), created, nil, "x"},
+			{int64(2), "anna", 7.0, []byte("0"), created, []byte("an"), nil},
+		},
+	}
+	results["ids"] = fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{[]byte("1")}, {int64(2)}}}
+	db, err := sql.Open("forceset-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	if err := ScanRows(rows, &users); err != nil {
+		t.Fatal(err)
+	}
+	nickname := "an"
+	expected := []User{
+		{ID: 1, UserName: "peter", Score: 9.5, Active: true, CreatedAt: created},
+		{ID: 2, UserName: "anna", Score: 7, Active: false, CreatedAt: created, Nickname: &nickname},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Fatalf("%#v", users)
+	}
+
+	rows, _ = db.Query("ids")
+	var ids []int
+	if err := ScanRows(rows, &ids); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Fatal(ids)
+	}
+
+	rows, _ = db.Query("users")
+	defer rows.Close()
+	var u User
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := ScanRow(rows, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.UserName != "peter" {
+		t.Fatalf("%#v", u)
+	}
+}
+
+type Audit struct {
+	UpdatedBy string
+}
+
+type Document struct {
+	Audit
+	Title string
+}
+
+func TestScanRowsEmbedded(t *testing.T) {
+	results["documents"] = fakeResult{
+		columns: []string{"title", "updated_by"},
+		rows:    [][]driver.Value{{"readme", "anna"}},
+	}
+	db, err := sql.Open("forceset-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("documents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []Document
+	if err := ScanRows(rows, &docs, forceset.RegisterEncoder("none", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Title != "readme" || docs[0].UpdatedBy != "anna" {
+		t.Fatalf("%#v", docs)
+	}
+}
@@ -0,0 +1,87 @@
+package forceset
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// isScanner reports whether value is addressable and its pointer a sql.Scanner.
+func isScanner(value reflect.Value) bool {
+	return value.Kind() != reflect.Ptr && value.CanAddr() && reflect.PtrTo(value.Type()).Implements(scannerType)
+}
+
+// setNull stores the NULL a driver.Valuer returned: an invalid value into
+// a sql.Scanner, the zero value into anything else, such as a nil pointer.
+func setNull(value reflect.Value) error {
+	for value.Kind() == reflect.Ptr && !value.CanSet() && !value.IsNil() {
+		value = value.Elem()
+	}
+	if isScanner(value) {
+		return value.Addr().Interface().(sql.Scanner).Scan(nil)
+	}
+	value.Set(reflect.Zero(value.Type()))
+	return nil
+}
+
+// tryScan sets a sql.Scanner value from a scalar i, "null" scans as NULL.
+// A Null-like struct, a value field followed by a Valid bool as sql.NullString
+// or sql.Null[T], whose Scan rejects i is set through forceSet instead.
+// ok is false when i is not a scalar.
+func tryScan(value reflect.Value, i interface{}, opt SetOption, tag string) (ok bool, err error) {
+	iv := reflect.ValueOf(i)
+	switch iv.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+	case reflect.Slice:
+		if iv.Type().Elem().Kind() != reflect.Uint8 {
+			return false, nil
+		}
+	default:
+		if iv.Type() != timeType {
+			return false, nil
+		}
+	}
+	scanner := value.Addr().Interface().(sql.Scanner)
+	switch o := i.(type) {
+	case string:
+		if strings.EqualFold(o, "null") {
+			return true, scanner.Scan(nil)
+		}
+	case []byte:
+		if strings.EqualFold(string(o), "null") {
+			return true, scanner.Scan(nil)
+		}
+	}
+	err = scanner.Scan(i)
+	if err == nil || !isNullLike(value.Type()) {
+		return true, err
+	}
+	if err := forceSet(value.Field(0), i, opt, tag); err != nil {
+		return true, err
+	}
+	value.Field(1).SetBool(true)
+	return true, nil
+}
+
+func isNullLike(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 {
+		return false
+	}
+	valid := typ.Field(1)
+	return typ.Field(0).PkgPath == "" && valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool
+}
@@ -0,0 +1,53 @@
+package forceset
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type nullRecord struct {
+	Name    sql.NullString
+	Age     sql.NullInt64
+	Score   sql.Null[float64]
+	Timeout sql.Null[time.Duration]
+	Nick    *string
+}
+
+func TestSetSQLNullTypes(t *testing.T) {
+	r := nullRecord{Name: sql.NullString{String: "old", Valid: true}}
+	err := Set(&r, map[string]interface{}{
+		"Name":    nil,
+		"Age":     []byte("42"),
+		"Score":   "9.5",
+		"Timeout": 3,
+		"Nick":    sql.NullString{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name.Valid || !r.Age.Valid || r.Age.Int64 != 42 || !r.Score.Valid || r.Score.V != 9.5 ||
+		!r.Timeout.Valid || r.Timeout.V != 3 || r.Nick != nil {
+		t.Fatalf("%#v", r)
+	}
+	err = Set(&r, map[string]interface{}{"Age": "NULL", "Nick": sql.NullString{String: "n", Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Age.Valid || r.Nick == nil || *r.Nick != "n" {
+		t.Fatalf("%#v", r)
+	}
+
+	m := map[string]interface{}{}
+	err = Set(&m, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["Name"] != nil || m["Age"] != nil || m["Score"] != 9.5 {
+		t.Fatalf("%#v", m)
+	}
+	var score float64
+	if err := Set(&score, r.Score); err != nil || score != 9.5 {
+		t.Fatal(score, err)
+	}
+}
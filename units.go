@@ -0,0 +1,179 @@
+package forceset
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type unitScale struct {
+	suffix string
+	factor float64
+}
+
+var (
+	// checked longest suffix first
+	byteUnits = []unitScale{
+		{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+		{"EB", 1e18}, {"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3}, {"kB", 1e3},
+		{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"K", 1e3}, {"k", 1e3}, {"B", 1},
+	}
+	byteFormat = []unitScale{
+		{"EiB", 1 << 60}, {"EB", 1e18}, {"PiB", 1 << 50}, {"PB", 1e15}, {"TiB", 1 << 40}, {"TB", 1e12},
+		{"GiB", 1 << 30}, {"GB", 1e9}, {"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"KB", 1e3},
+	}
+	siUnits = []unitScale{
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3}, {"K", 1e3},
+		{"m", 1e-3}, {"u", 1e-6}, {"µ", 1e-6}, {"n", 1e-9},
+	}
+	siFormat = []unitScale{
+		{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3},
+		{"", 1}, {"m", 1e-3}, {"u", 1e-6}, {"n", 1e-9},
+	}
+)
+
+// setUnit parses a string like "512MiB", "10k" or "75%" into a numeric value
+// by the "unit:bytes", "unit:si" or "unit:percent" tag option. A percentage
+// is a ratio in a float, 0.75, and a number of percent in an integer, 75.
+// ok is false when the tag has no unit or i is not a string.
+func setUnit(value reflect.Value, i interface{}, opt SetOption, tag string) (ok bool, err error) {
+	unit, hasUnit := tagOption(tag, "unit")
+	if !hasUnit {
+		return false, nil
+	}
+	var s string
+	switch o := i.(type) {
+	case string:
+		s = o
+	case []byte:
+		s = string(o)
+	default:
+		return false, nil
+	}
+	isFloat := value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+	default:
+		return false, nil
+	}
+	s = strings.TrimSpace(s)
+	var scale *big.Rat
+	switch unit {
+	case "bytes":
+		s, scale = cutUnit(s, byteUnits)
+	case "si":
+		s, scale = cutUnit(s, siUnits)
+	case "percent":
+		scale = big.NewRat(1, 1)
+		if strings.HasSuffix(s, "%") {
+			s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+			if isFloat {
+				scale = big.NewRat(1, 100)
+			}
+		} else if !isFloat {
+			scale = big.NewRat(100, 1)
+		}
+	default:
+		return true, errors.New("unknown unit " + unit)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/") {
+		return true, errors.New("parse " + strconv.Quote(s) + " with unit " + unit + " failed")
+	}
+	r.Mul(r, scale)
+	if isFloat {
+		f, _ := r.Float64()
+		value.SetFloat(f)
+		return true, nil
+	}
+	return true, forceSet(value, r, opt, "")
+}
+
+func cutUnit(s string, units []unitScale) (string, *big.Rat) {
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			return strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), scaleRat(u.factor)
+		}
+	}
+	return s, big.NewRat(1, 1)
+}
+
+// scaleRat is factor exactly, powers of ten below one in decimal.
+func scaleRat(factor float64) *big.Rat {
+	if factor >= 1 {
+		return new(big.Rat).SetFloat64(factor)
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(factor, 'g', -1, 64))
+	return r
+}
+
+// formatUnit is the inverse of setUnit for a numeric i, ok is false when the
+// tag has no unit or i is not a number.
+func formatUnit(i interface{}, tag string) (s string, ok bool, err error) {
+	unit, hasUnit := tagOption(tag, "unit")
+	if !hasUnit {
+		return "", false, nil
+	}
+	iv := reflect.ValueOf(i)
+	// exact is nil for floats
+	var exact *big.Rat
+	var f float64
+	switch iv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		exact = new(big.Rat).SetInt64(iv.Int())
+		f = float64(iv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		exact = new(big.Rat).SetUint64(iv.Uint())
+		f = float64(iv.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = iv.Float()
+	default:
+		return "", false, nil
+	}
+	switch unit {
+	case "bytes":
+		return formatScaled(f, exact, byteFormat, "B"), true, nil
+	case "si":
+		return formatScaled(f, exact, siFormat, ""), true, nil
+	case "percent":
+		if exact != nil {
+			return exact.RatString() + "%", true, nil
+		}
+		return strconv.FormatFloat(f*100, 'f', -1, 64) + "%", true, nil
+	}
+	return "", true, errors.New("unknown unit " + unit)
+}
+
+// formatScaled uses the largest unit not above f that parses back to f, or
+// else no unit. An integer, given as exact, must be matched exactly, a float
+// within float64.
+func formatScaled(f float64, exact *big.Rat, units []unitScale, plain string) string {
+	abs := math.Abs(f)
+	for _, u := range units {
+		if abs < u.factor {
+			continue
+		}
+		if u.factor == 1 {
+			break
+		}
+		m := strconv.FormatFloat(f/u.factor, 'f', -1, 64)
+		r, _ := new(big.Rat).SetString(m)
+		r.Mul(r, scaleRat(u.factor))
+		if exact == nil {
+			if back, _ := r.Float64(); back == f {
+				return m + u.suffix
+			}
+		} else if r.Cmp(exact) == 0 {
+			return m + u.suffix
+		}
+	}
+	if exact != nil {
+		return exact.RatString() + plain
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64) + plain
+}
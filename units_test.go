@@ -0,0 +1,62 @@
+package forceset
+
+import "testing"
+
+type limitsConfig struct {
+	Memory    int64   `json:"memory;unit:bytes"`
+	Disk      uint64  `json:"disk;unit:bytes"`
+	Rate      int     `json:"rate;unit:si"`
+	Latency   float64 `json:"latency;unit:si"`
+	Threshold float64 `json:"threshold;unit:percent"`
+	Share     int     `json:"share;unit:percent"`
+}
+
+func TestSetUnits(t *testing.T) {
+	var bad limitsConfig
+	if err := Set(&bad, map[string]string{"share": "12.5 %"}); err == nil {
+		t.Fatal("expected error for fractional share got:", bad.Share)
+	}
+	var c limitsConfig
+	err := Set(&c, map[string]string{
+		"memory":    "512MiB",
+		"disk":      "1.5 TB",
+		"rate":      "10k",
+		"latency":   "250m",
+		"threshold": "75%",
+		"share":     "12 %",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := limitsConfig{Memory: 512 << 20, Disk: 1.5e12, Rate: 10000, Latency: 0.25, Threshold: 0.75, Share: 12}
+	if c != expected {
+		t.Fatalf("%#v", c)
+	}
+	if err := Set(&bad, map[string]string{"memory": "1.0001KiB"}); err == nil {
+		t.Fatal("expected error for fractional bytes got:", bad.Memory)
+	}
+
+	m := map[string]string{}
+	if err := Set(&m, c); err != nil {
+		t.Fatal(err)
+	}
+	if m["memory"] != "512MiB" || m["disk"] != "1.5TB" || m["rate"] != "10k" ||
+		m["latency"] != "250m" || m["threshold"] != "75%" || m["share"] != "12%" {
+		t.Fatalf("%#v", m)
+	}
+	var back limitsConfig
+	if err := Set(&back, m); err != nil || back.Memory != c.Memory || back.Threshold != c.Threshold {
+		t.Fatalf("%#v %v", back, err)
+	}
+	back.Rate = 250
+	if err := Set(&m, back); err != nil || m["rate"] != "250" {
+		t.Fatal(m["rate"], err)
+	}
+	back.Memory = 1<<53 + 1
+	if err := Set(&m, back); err != nil || m["memory"] != "9.007199254740993PB" {
+		t.Fatal(m["memory"], err)
+	}
+	if err := Set(&back, m); err != nil || back.Memory != 1<<53+1 {
+		t.Fatal(back.Memory, err)
+	}
+}
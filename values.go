@@ -0,0 +1,196 @@
+package forceset
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Values are the values of one parameter such as a url.Values key. A list
+// destination takes all of them, split again by a "sep" tag option, any other
+// destination takes the first.
+type Values []string
+
+func (values Values) pick(typ reflect.Type, tag string) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch {
+	case isList(typ):
+		if sep, ok := tagOption(tag, "sep"); ok {
+			return strings.Join(values, sep)
+		}
+		return []string(values)
+	case len(values) == 0:
+		return nil
+	case typ.Kind() == reflect.Interface && len(values) > 1:
+		return []string(values)
+	}
+	return values[0]
+}
+
+// valuesTree nests url.Values by the dots and brackets of their keys, so
+// "items[0].name" becomes {"items": {"0": {"name": ...}}} and
+// "filter[status]" becomes {"filter": {"status": ...}}.
+func valuesTree(values url.Values) map[string]interface{} {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tree := map[string]interface{}{}
+	for _, key := range keys {
+		segments := splitValuesKey(key)
+		node := tree
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[segment] = child
+			}
+			node = child
+		}
+		node[segments[len(segments)-1]] = Values(values[key])
+	}
+	return tree
+}
+
+// splitValuesKey splits "items[0].name" into "items", "0" and "name", an
+// empty "[]" as in "tags[]" is dropped.
+func splitValuesKey(key string) []string {
+	var segments []string
+	for key != "" {
+		end := strings.IndexAny(key, ".[")
+		if end < 0 {
+			segments = append(segments, key)
+			break
+		}
+		if end > 0 {
+			segments = append(segments, key[:end])
+		}
+		if key[end] == '.' {
+			key = key[end+1:]
+			continue
+		}
+		closing := strings.IndexByte(key[end:], ']')
+		if closing < 0 {
+			segments = append(segments, key[end:])
+			break
+		}
+		if closing > 1 {
+			segments = append(segments, key[end+1:end+closing])
+		}
+		key = key[end+closing+1:]
+	}
+	if len(segments) == 0 {
+		segments = []string{""}
+	}
+	return segments
+}
+
+// ToValues flattens a struct or map into url.Values, the inverse of setting a
+// struct from url.Values: nested struct fields become "parent.child", map
+// entries "parent[key]", lists of scalars repeat their key and lists of
+// composites become "parent[0].child".
+func ToValues(src interface{}, opts ...Option) (values url.Values, err error) {
+	defer recoverPanic(&err)
+	opt := NewSetOption(opts...)
+	values = url.Values{}
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+	default:
+		return nil, errors.New("to url values from type(" + reflect.TypeOf(src).String() + ") failed")
+	}
+	if err := toValues(values, "", v, opt); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func toValues(values url.Values, key string, v reflect.Value, opt SetOption) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		sortKeys(keys)
+		for _, k := range keys {
+			name := toString(k.Interface(), opt)
+			if key != "" {
+				name = key + "[" + name + "]"
+			}
+			if err := toValues(values, name, v.MapIndex(k), opt); err != nil {
+				return err
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Struct && isNestedStruct(v.Type(), opt):
+		return struct2values(values, key, v, opt)
+	case isList(v.Type()):
+		for i := 0; i < v.Len(); i++ {
+			elm := v.Index(i)
+			if isComposite(elm.Type(), opt) {
+				if err := toValues(values, key+"["+strconv.Itoa(i)+"]", elm, opt); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := toValues(values, key, elm, opt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var s string
+	if err := forceSet(reflect.ValueOf(&s), v.Interface(), opt, ""); err != nil {
+		return withPath(err, key)
+	}
+	values.Add(key, s)
+	return nil
+}
+
+func struct2values(values url.Values, key string, v reflect.Value, opt SetOption) error {
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		st := typ.Field(i)
+		if st.Anonymous {
+			if err := toValues(values, key, field, opt); err != nil {
+				return err
+			}
+			continue
+		}
+		if st.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(strings.Split(st.Tag.Get(opt.Tag), ";")[0], " ")[0]
+		if name == "" {
+			name = st.Name
+		}
+		if key != "" {
+			name = key + "." + name
+		}
+		if err := toValues(values, name, field, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isComposite(typ reflect.Type, opt SetOption) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Map || typ.Kind() == reflect.Interface || isList(typ) || isNestedStruct(typ, opt)
+}
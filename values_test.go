@@ -0,0 +1,64 @@
+package forceset
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type searchItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type searchQuery struct {
+	Q      string            `json:"q"`
+	Page   int               `json:"page"`
+	Tags   []string          `json:"tags"`
+	Items  []searchItem      `json:"items"`
+	Filter map[string]string `json:"filter"`
+	Sort   struct {
+		By   string `json:"by"`
+		Desc bool   `json:"desc"`
+	} `json:"sort"`
+}
+
+func TestSetFromValues(t *testing.T) {
+	values, err := url.ParseQuery("q=go&q=rust&page=2&tags[]=a&tags[]=b&items[1].name=y&items[0].name=x&items[0].count=3" +
+		"&filter[status]=open&sort.by=date&sort.desc=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var q searchQuery
+	err = Set(&q, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected searchQuery
+	expected.Q = "go"
+	expected.Page = 2
+	expected.Tags = []string{"a", "b"}
+	expected.Items = []searchItem{{Name: "x", Count: 3}, {Name: "y"}}
+	expected.Filter = map[string]string{"status": "open"}
+	expected.Sort.By = "date"
+	expected.Sort.Desc = true
+	if !reflect.DeepEqual(q, expected) {
+		t.Fatalf("%#v", q)
+	}
+
+	encoded, err := ToValues(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded searchQuery
+	err = Set(&decoded, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("%s decoded as %#v", encoded.Encode(), decoded)
+	}
+	if encoded.Get("items[0].name") != "x" || encoded.Get("filter[status]") != "open" || len(encoded["tags"]) != 2 {
+		t.Fatal(encoded.Encode())
+	}
+}